@@ -0,0 +1,153 @@
+package vyxclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Authentication failure reasons passed to OnAuthFailed
+const (
+	AuthFailureInvalidToken    = "invalid_token"
+	AuthFailureTokenRevoked    = "token_revoked"
+	AuthFailureTokenExpired    = "token_expired"
+	AuthFailureAccountDisabled = "account_disabled"
+	AuthFailureRateLimited     = "rate_limited"
+	AuthFailureServerError     = "server_error"
+	AuthFailureTimeout         = "timeout"
+	AuthFailureProtocolError   = "protocol_error"
+)
+
+// DisconnectTerminalPrefix starts OnDisconnected reasons after which the
+// client won't reconnect on its own, e.g. "terminal: auth_failed: invalid_token"
+const DisconnectTerminalPrefix = "terminal: "
+
+// AuthFailedCallback is an optional extension of Callback
+// OnAuthFailed fires when the server rejects authentication, with one of
+// the AuthFailure reasons.
+type AuthFailedCallback interface {
+	OnAuthFailed(reason string)
+}
+
+// authError is an authentication rejected by the server or abandoned
+type authError struct {
+	reason  string
+	message string
+}
+
+func (e *authError) Error() string {
+	if e.message == "" {
+		return fmt.Sprintf("authentication failed: %s", e.reason)
+	}
+	return fmt.Sprintf("authentication failed: %s: %s", e.reason, e.message)
+}
+
+// Is makes errors.Is(err, errAuthFailed) match every authError
+func (e *authError) Is(target error) bool {
+	return target == errAuthFailed
+}
+
+// permanent reports whether retrying with the same token can't succeed
+func (e *authError) permanent() bool {
+	switch e.reason {
+	case AuthFailureInvalidToken, AuthFailureTokenRevoked, AuthFailureAccountDisabled:
+		return true
+	}
+	return false
+}
+
+// serverSide reports whether the failure lies with the server rather than the token
+func (e *authError) serverSide() bool {
+	switch e.reason {
+	case AuthFailureServerError, AuthFailureTimeout, AuthFailureProtocolError, AuthFailureRateLimited:
+		return true
+	}
+	return false
+}
+
+// authFailurePayload is the structured form of an auth "error" message's data
+type authFailurePayload struct {
+	Code    string `json:"code"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// knownAuthFailures are reasons a server may send verbatim
+var knownAuthFailures = map[string]bool{
+	AuthFailureInvalidToken:    true,
+	AuthFailureTokenRevoked:    true,
+	AuthFailureTokenExpired:    true,
+	AuthFailureAccountDisabled: true,
+	AuthFailureRateLimited:     true,
+	AuthFailureServerError:     true,
+}
+
+// parseAuthFailure derives a reason from the data of an auth "error" message
+// Only a structured code or reason is trusted. Free text, or a code this
+// client doesn't know, is a retryable server error: guessing a permanent
+// reason from wording like "token service unavailable" would stop the
+// client reconnecting until the app replaces a perfectly good token.
+func parseAuthFailure(data string) *authError {
+	var payload authFailurePayload
+	if json.Unmarshal([]byte(data), &payload) == nil {
+		code := strings.ToLower(strings.TrimSpace(payload.Code))
+		if code == "" {
+			code = strings.ToLower(strings.TrimSpace(payload.Reason))
+		}
+		if knownAuthFailures[code] {
+			return &authError{reason: code, message: payload.Message}
+		}
+		if payload.Message != "" {
+			data = payload.Message
+		}
+	}
+	return &authError{reason: AuthFailureServerError, message: data}
+}
+
+// isDecodeError reports whether err came from a malformed message rather than the stream
+func isDecodeError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
+}
+
+// SetAuthTimeout sets how long to wait for each authentication response
+// Multi-step auth gets a fresh timeout per step. Takes effect at the next
+// authentication.
+// Returns error message or empty string on success
+func (c *Client) SetAuthTimeout(timeoutMillis int) string {
+	if timeoutMillis <= 0 {
+		return "timeout must be positive"
+	}
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.AuthTimeoutMs = timeoutMillis
+	})
+	return ""
+}
+
+// notifyAuthFailed tells the app why authentication failed
+func (c *Client) notifyAuthFailed(reason string) {
+	if cb, ok := c.callback.(AuthFailedCallback); ok {
+		cb.OnAuthFailed(reason)
+	}
+}
+
+// holdForNewToken pauses reconnection after a permanent auth failure
+// SetToken or SetTokenProvider lift the hold; ResumeReconnection retries as is.
+func (c *Client) holdForNewToken(authErr *authError) {
+	reason := DisconnectTerminalPrefix + reasonAuthFailed + ": " + authErr.reason
+	c.logError(fmt.Sprintf("Not reconnecting until the token is replaced (%s)", authErr.reason))
+	c.awaitingToken.Store(true)
+	c.PauseReconnection()
+	if c.callback != nil {
+		c.callback.OnDisconnected(reason)
+	}
+}
+
+// releaseTokenHold resumes reconnection held by holdForNewToken
+func (c *Client) releaseTokenHold() {
+	if c.awaitingToken.CompareAndSwap(true, false) {
+		c.ResumeReconnection()
+	}
+}
@@ -0,0 +1,28 @@
+package vyxclient
+
+import "testing"
+
+func TestParseAuthFailure(t *testing.T) {
+	tests := []struct {
+		data      string
+		reason    string
+		permanent bool
+	}{
+		{`{"code":"invalid_token","message":"bad token"}`, AuthFailureInvalidToken, true},
+		{`{"reason":"TOKEN_REVOKED"}`, AuthFailureTokenRevoked, true},
+		{`{"code":"token_expired"}`, AuthFailureTokenExpired, false},
+		{`{"code":"rate_limited"}`, AuthFailureRateLimited, false},
+		{`{"code":"something_new","message":"unauthorized"}`, AuthFailureServerError, false},
+		{"token service unavailable", AuthFailureServerError, false},
+		{"unauthorized", AuthFailureServerError, false},
+		{"failed to generate session", AuthFailureServerError, false},
+		{"", AuthFailureServerError, false},
+	}
+	for _, tt := range tests {
+		got := parseAuthFailure(tt.data)
+		if got.reason != tt.reason || got.permanent() != tt.permanent {
+			t.Errorf("parseAuthFailure(%q) = %s (permanent %v), want %s (permanent %v)",
+				tt.data, got.reason, got.permanent(), tt.reason, tt.permanent)
+		}
+	}
+}
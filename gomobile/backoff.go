@@ -0,0 +1,120 @@
+package vyxclient
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// ReconnectPolicy configures the delay between reconnection attempts
+// The n-th consecutive failure waits InitialDelayMs * Multiplier^(n-1),
+// capped at MaxDelayMs, then randomized by ±JitterFraction so that many
+// devices reconnecting after a server restart spread out.
+type ReconnectPolicy struct {
+	InitialDelayMs int
+	MaxDelayMs     int
+	Multiplier     float64
+	// JitterFraction is between 0 (none) and 1
+	JitterFraction float64
+}
+
+// DefaultReconnectPolicy returns the policy used by NewClient
+// 1s -> 2s -> 4s -> ... -> 64s, without jitter.
+func DefaultReconnectPolicy() *ReconnectPolicy {
+	cfg := defaultConfig()
+	return &ReconnectPolicy{
+		InitialDelayMs: cfg.ReconnectInitialDelayMs,
+		MaxDelayMs:     cfg.ReconnectMaxDelayMs,
+		Multiplier:     cfg.ReconnectMultiplier,
+		JitterFraction: cfg.ReconnectJitter,
+	}
+}
+
+// SetReconnectPolicy replaces the reconnection backoff, nil restores the default
+// Returns error message or empty string on success
+func (c *Client) SetReconnectPolicy(policy *ReconnectPolicy) string {
+	if policy == nil {
+		policy = DefaultReconnectPolicy()
+	}
+
+	cfg := c.getConfig()
+	cfg.ReconnectInitialDelayMs = policy.InitialDelayMs
+	cfg.ReconnectMaxDelayMs = policy.MaxDelayMs
+	cfg.ReconnectMultiplier = policy.Multiplier
+	cfg.ReconnectJitter = policy.JitterFraction
+	if err := validateReconnectPolicy(cfg); err != nil {
+		return err.Error()
+	}
+
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.ReconnectInitialDelayMs = policy.InitialDelayMs
+		cfg.ReconnectMaxDelayMs = policy.MaxDelayMs
+		cfg.ReconnectMultiplier = policy.Multiplier
+		cfg.ReconnectJitter = policy.JitterFraction
+	})
+	return ""
+}
+
+// validateReconnectPolicy checks the backoff settings
+func validateReconnectPolicy(cfg clientConfig) error {
+	if cfg.ReconnectInitialDelayMs <= 0 {
+		return errors.New("reconnect initial delay must be positive")
+	}
+	if cfg.ReconnectMaxDelayMs < cfg.ReconnectInitialDelayMs {
+		return errors.New("reconnect max delay must not be below the initial delay")
+	}
+	if cfg.ReconnectMultiplier < 1 {
+		return errors.New("reconnect multiplier must be at least 1")
+	}
+	if cfg.ReconnectJitter < 0 || cfg.ReconnectJitter > 1 {
+		return errors.New("reconnect jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+// backoffDelay computes the delay after failures consecutive failures
+func backoffDelay(cfg clientConfig, failures int) time.Duration {
+	initial := float64(cfg.ReconnectInitialDelayMs)
+	max := float64(cfg.ReconnectMaxDelayMs)
+
+	delay := initial
+	if failures > 1 {
+		delay = initial * math.Pow(cfg.ReconnectMultiplier, float64(failures-1))
+	}
+	if delay > max || math.IsInf(delay, 0) {
+		delay = max
+	}
+
+	if cfg.ReconnectJitter > 0 {
+		delay *= 1 + cfg.ReconnectJitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay) * time.Millisecond
+}
+
+// SetReconnectStableTime sets how long a connection must last to reset the backoff
+// A connection dropping sooner counts as another failure, so a connection
+// that keeps dropping right after connecting backs off instead of
+// reconnecting at the initial delay forever. 0 resets on every connect.
+func (c *Client) SetReconnectStableTime(stableMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.ReconnectStableMs = stableMillis
+	})
+}
+
+// settleBackoff updates the failure count once a connection ended
+// Deliberate reconnects and connections that lasted reset the backoff.
+func (c *Client) settleBackoff(connectedFor time.Duration, deliberate bool) {
+	stable := time.Duration(c.getConfig().ReconnectStableMs) * time.Millisecond
+
+	c.retryMutex.Lock()
+	defer c.retryMutex.Unlock()
+
+	if deliberate || connectedFor >= stable {
+		c.consecutiveFailures = 0
+		return
+	}
+	c.consecutiveFailures++
+	c.logDebug(fmt.Sprintf("Connection lasted only %v, backing off (failure %d)", connectedFor.Round(time.Millisecond), c.consecutiveFailures))
+}
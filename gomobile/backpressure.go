@@ -0,0 +1,95 @@
+package vyxclient
+
+import (
+	"fmt"
+	"time"
+)
+
+// Per-connection buffering
+//
+// Data from the server for a Go-side connection is queued until its target
+// accepts it. Each connection may hold at most ConnBufferBytes queued bytes
+// (a single chunk larger than the budget is still accepted into an empty
+// buffer). What happens above the budget depends on ConnBufferPolicy:
+//
+//   - "close" (default): the connection is closed with reason "buffer_full".
+//     Data is never dropped silently, since a gap would corrupt the stream.
+//   - "block": reading from the server pauses until the target catches up,
+//     pushing back on the server through QUIC flow control. Connections
+//     sharing the control stream stall together unless per-connection
+//     streams were negotiated; after bufferBlockTimeout the connection is
+//     closed as with "close".
+
+// Buffer policies accepted by SetConnectionBuffer
+const (
+	BufferPolicyClose = "close"
+	BufferPolicyBlock = "block"
+)
+
+// reasonBufferFull closes connections whose target can't keep up
+const reasonBufferFull = "buffer_full"
+
+// bufferBlockTimeout bounds how long the "block" policy stalls the server stream
+const bufferBlockTimeout = 30 * time.Second
+
+// bufferPollInterval is how often a blocked delivery rechecks the buffer
+const bufferPollInterval = 5 * time.Millisecond
+
+// SetConnectionBuffer sets the per-connection byte budget and overflow policy
+// maxBytes 0 removes the budget; policy is "close" or "block".
+// Returns error message or empty string on success
+func (c *Client) SetConnectionBuffer(maxBytes int64, policy string) string {
+	if maxBytes < 0 {
+		return "maxBytes must not be negative"
+	}
+	if policy != BufferPolicyClose && policy != BufferPolicyBlock {
+		return fmt.Sprintf("unknown buffer policy %q", policy)
+	}
+
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.ConnBufferBytes = maxBytes
+		cfg.ConnBufferPolicy = policy
+	})
+	return ""
+}
+
+// reserveBuffer checks that n more bytes fit in the connection's budget
+// Under the "block" policy it waits for the target to drain the buffer.
+// Returns false if the data must not be queued.
+func (c *Client) reserveBuffer(cc *Connection, n int) bool {
+	cfg := c.getConfig()
+	budget := cfg.ConnBufferBytes
+
+	var deadline time.Time
+	for {
+		buffered := cc.enqueued.Load() - cc.written.Load()
+		if budget <= 0 || buffered == 0 || buffered+int64(n) <= budget {
+			c.recordBufferLevel(buffered + int64(n))
+			return true
+		}
+		if cfg.ConnBufferPolicy != BufferPolicyBlock {
+			return false
+		}
+
+		if deadline.IsZero() {
+			deadline = time.Now().Add(bufferBlockTimeout)
+		} else if time.Now().After(deadline) {
+			return false
+		}
+		select {
+		case <-time.After(bufferPollInterval):
+		case <-cc.ctx.Done():
+			return false
+		}
+	}
+}
+
+// recordBufferLevel raises the buffer high-water mark to level if higher
+func (c *Client) recordBufferLevel(level int64) {
+	for {
+		high := c.stats.bufferHighWater.Load()
+		if level <= high || c.stats.bufferHighWater.CompareAndSwap(high, level) {
+			return
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package vyxclient
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/quic-go/quic-go"
+)
+
+// Binary data framing
+//
+// When the server accepts the binary_data capability, "data" messages are
+// sent as length-prefixed binary frames instead of JSON with a base64
+// payload, saving the encoding CPU and a third of the bytes. Control messages
+// stay newline-terminated JSON on the same stream. A binary frame is:
+//
+//	0x00 | id length (1 byte) | id | payload length (4 bytes, big endian) | payload
+//
+// A compressed payload (see compress.go) starts the frame with 0x01 instead.
+// JSON messages never start with either, so the reader tells them apart by
+// their first byte. Binary frames carry no "ts", so one-way delay samples
+// only come from control messages. Payloads of encrypted connections are
+// sealed as usual, just not base64 encoded.
+
+// binaryFrameMarker starts every binary frame, binaryFrameDeflate one with a
+// compressed payload
+const (
+	binaryFrameMarker  = 0x00
+	binaryFrameDeflate = 0x01
+)
+
+// maxBinaryFramePayload rejects frames that can't be legitimate
+const maxBinaryFramePayload = 16 << 20
+
+// SetBinaryData offers binary framing of data messages
+// Takes effect at the next authentication; the server must accept the
+// binary_data capability, otherwise data stays base64 in JSON.
+func (c *Client) SetBinaryData(enabled bool) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.BinaryData = enabled
+	})
+}
+
+// messageReader reads messages from a stream
+type messageReader interface {
+	next(msg *Message) error
+}
+
+// jsonReader reads JSON messages only
+type jsonReader struct {
+	decoder *json.Decoder
+}
+
+func (jr jsonReader) next(msg *Message) error {
+	return jr.decoder.Decode(msg)
+}
+
+// frameReader reads newline-terminated JSON messages and binary data frames
+type frameReader struct {
+	r *bufio.Reader
+}
+
+func (fr *frameReader) next(msg *Message) error {
+	for {
+		b, err := fr.r.Peek(1)
+		if err != nil {
+			return err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			fr.r.Discard(1)
+			continue
+		case binaryFrameMarker, binaryFrameDeflate:
+			return fr.nextFrame(msg)
+		}
+		break
+	}
+
+	line, err := fr.r.ReadBytes('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && len(line) > 0) {
+		return err
+	}
+	*msg = Message{}
+	return json.Unmarshal(line, msg)
+}
+
+// nextFrame reads one binary data frame
+func (fr *frameReader) nextFrame(msg *Message) error {
+	var header [2]byte
+	if _, err := io.ReadFull(fr.r, header[:]); err != nil {
+		return err
+	}
+	id := make([]byte, header[1])
+	if _, err := io.ReadFull(fr.r, id); err != nil {
+		return err
+	}
+	var length [4]byte
+	if _, err := io.ReadFull(fr.r, length[:]); err != nil {
+		return err
+	}
+	n := binary.BigEndian.Uint32(length[:])
+	if n > maxBinaryFramePayload {
+		return fmt.Errorf("binary frame of %d bytes exceeds %d", n, maxBinaryFramePayload)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(fr.r, payload); err != nil {
+		return err
+	}
+
+	*msg = Message{Type: "data", ID: string(id), raw: payload}
+	if header[0] == binaryFrameDeflate {
+		msg.Enc = encDeflate
+	}
+	return nil
+}
+
+// streamReader returns the reader for messages on an authenticated stream
+// decoder's read-ahead is carried over, so nothing buffered is lost.
+func (c *Client) streamReader(stream *quic.Stream, decoder *json.Decoder) messageReader {
+	if !c.hasCap(capBinaryData) {
+		return jsonReader{decoder: decoder}
+	}
+	rest := io.MultiReader(decoder.Buffered(), &countingReader{r: stream, n: &c.stats.bytesReceived})
+	return &frameReader{r: bufio.NewReader(rest)}
+}
+
+// binaryDataFrame encodes a data message as a binary frame
+// Returns false if msg should go as JSON: binary framing wasn't negotiated,
+// the ID is too long or the app's data isn't base64.
+func (c *Client) binaryDataFrame(msg *Message) ([]byte, bool, error) {
+	if !c.hasCap(capBinaryData) || msg.ID == "" || len(msg.ID) > 255 {
+		return nil, false, nil
+	}
+
+	payload := msg.raw
+	if payload == nil {
+		decoded, err := base64.StdEncoding.DecodeString(msg.Data)
+		if err != nil {
+			return nil, false, nil
+		}
+		payload = decoded
+	}
+	marker := byte(binaryFrameMarker)
+	payload, compressed := c.compressPayload(payload)
+	if compressed {
+		marker = binaryFrameDeflate
+	}
+	payload, err := c.sealBytes(msg.ID, payload)
+	if err != nil {
+		return nil, false, err
+	}
+
+	frame := make([]byte, 0, 2+len(msg.ID)+4+len(payload))
+	frame = append(frame, marker, byte(len(msg.ID)))
+	frame = append(frame, msg.ID...)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(payload)))
+	frame = append(frame, payload...)
+	return frame, true, nil
+}
+
+// handleBinaryData delivers the payload of a binary data frame
+func (c *Client) handleBinaryData(msg *Message) {
+	data, err := c.openBytes(msg.ID, msg.raw)
+	if err != nil {
+		c.log(fmt.Sprintf("Dropping connection %s: %v", msg.ID, err))
+		c.notifyError(reasonDecryptFailed, err.Error())
+		c.closeWithReason(msg.ID, reasonDecryptFailed)
+		return
+	}
+	if data, err = decompressPayload(msg.Enc, data); err != nil {
+		c.log(fmt.Sprintf("Dropping connection %s: %v", msg.ID, err))
+		c.notifyError(reasonDecompressFailed, err.Error())
+		c.closeWithReason(msg.ID, reasonDecompressFailed)
+		return
+	}
+	if c.deliverBytes(msg.ID, data) {
+		return
+	}
+	c.dispatchMessage("data", msg.ID, "", base64.StdEncoding.EncodeToString(data))
+}
+
+// dataPayload returns the bytes of a received data message
+func (msg *Message) dataPayload() ([]byte, error) {
+	if msg.raw != nil {
+		return decompressPayload(msg.Enc, msg.raw)
+	}
+	data, err := base64.StdEncoding.DecodeString(msg.Data)
+	if err != nil {
+		return nil, err
+	}
+	return decompressPayload(msg.Enc, data)
+}
+
+// dataMessage builds a data message for a chunk read from a target
+// chunk is only used until the message is sent.
+func (c *Client) dataMessage(id string, chunk []byte) *Message {
+	if c.hasCap(capBinaryData) {
+		return &Message{Type: "data", ID: id, raw: chunk}
+	}
+	return &Message{Type: "data", ID: id, Data: base64.StdEncoding.EncodeToString(chunk)}
+}
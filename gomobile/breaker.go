@@ -0,0 +1,170 @@
+package vyxclient
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// refusedCircuitOpen is the refusal reason while a destination's breaker is open
+const refusedCircuitOpen = "circuit_open"
+
+// Circuit breaker states
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half_open"
+)
+
+// destinationBreaker tracks dial failures to one destination
+type destinationBreaker struct {
+	state         string
+	failures      []time.Time
+	openedAt      time.Time
+	probeInFlight bool
+}
+
+// circuitBreakers holds a breaker per destination address
+// While a breaker is open, connects to that destination are refused with
+// "circuit_open". After the cooldown one connect is let through as a probe
+// (half-open): success closes the breaker, failure opens it again.
+type circuitBreakers struct {
+	mu           sync.Mutex
+	destinations map[string]*destinationBreaker
+	pendingAddrs map[string]string // connect id -> addr, for ConfirmConnection
+}
+
+// SetCircuitBreaker configures the per-destination circuit breaker
+// After failures consecutive dial failures within windowMillis, connects to
+// that destination are refused for cooldownMillis. failures 0 disables.
+func (c *Client) SetCircuitBreaker(failures int, windowMillis int, cooldownMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.BreakerFailures = failures
+		cfg.BreakerWindowMs = windowMillis
+		cfg.BreakerCooldownMs = cooldownMillis
+	})
+}
+
+// breakerAllow reports whether a connect to addr may proceed
+func (c *Client) breakerAllow(addr string) bool {
+	cfg := c.getConfig()
+	if cfg.BreakerFailures <= 0 {
+		return true
+	}
+
+	c.breakers.mu.Lock()
+	defer c.breakers.mu.Unlock()
+
+	b, ok := c.breakers.destinations[addr]
+	if !ok {
+		return true
+	}
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < time.Duration(cfg.BreakerCooldownMs)*time.Millisecond {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.probeInFlight = true
+		c.log(fmt.Sprintf("Circuit for %s half-open, probing", addr))
+		return true
+	case breakerHalfOpen:
+		if b.probeInFlight {
+			return false
+		}
+		b.probeInFlight = true
+		return true
+	}
+	return true
+}
+
+// breakerRecord records the outcome of a dial to addr
+func (c *Client) breakerRecord(addr string, success bool) {
+	cfg := c.getConfig()
+	if cfg.BreakerFailures <= 0 || addr == "" {
+		return
+	}
+
+	c.breakers.mu.Lock()
+	defer c.breakers.mu.Unlock()
+
+	if c.breakers.destinations == nil {
+		c.breakers.destinations = make(map[string]*destinationBreaker)
+	}
+	b, ok := c.breakers.destinations[addr]
+
+	if success {
+		if ok {
+			if b.state != breakerClosed {
+				c.log(fmt.Sprintf("Circuit for %s closed", addr))
+			}
+			delete(c.breakers.destinations, addr)
+		}
+		return
+	}
+
+	if !ok {
+		b = &destinationBreaker{state: breakerClosed}
+		c.breakers.destinations[addr] = b
+	}
+
+	now := time.Now()
+	if b.state == breakerHalfOpen {
+		// The probe failed
+		b.state = breakerOpen
+		b.openedAt = now
+		b.probeInFlight = false
+		c.log(fmt.Sprintf("Circuit for %s re-opened", addr))
+		return
+	}
+
+	window := time.Duration(cfg.BreakerWindowMs) * time.Millisecond
+	recent := b.failures[:0]
+	for _, t := range b.failures {
+		if now.Sub(t) <= window {
+			recent = append(recent, t)
+		}
+	}
+	b.failures = append(recent, now)
+
+	if b.state == breakerClosed && len(b.failures) >= cfg.BreakerFailures {
+		b.state = breakerOpen
+		b.openedAt = now
+		b.failures = nil
+		c.log(fmt.Sprintf("Circuit for %s opened after %d failures", addr, cfg.BreakerFailures))
+	}
+}
+
+// trackPendingConnect remembers the addr of a connect handled by the app
+func (c *Client) trackPendingConnect(id string, addr string) {
+	c.breakers.mu.Lock()
+	defer c.breakers.mu.Unlock()
+
+	if c.breakers.pendingAddrs == nil {
+		c.breakers.pendingAddrs = make(map[string]string)
+	}
+	c.breakers.pendingAddrs[id] = addr
+}
+
+// takePendingConnect returns and forgets the addr of an app-handled connect
+func (c *Client) takePendingConnect(id string) string {
+	c.breakers.mu.Lock()
+	defer c.breakers.mu.Unlock()
+
+	addr := c.breakers.pendingAddrs[id]
+	delete(c.breakers.pendingAddrs, id)
+	return addr
+}
+
+// breakerSnapshot returns the state of every tracked destination
+func (c *Client) breakerSnapshot() map[string]string {
+	c.breakers.mu.Lock()
+	defer c.breakers.mu.Unlock()
+
+	snap := make(map[string]string, len(c.breakers.destinations))
+	for addr, b := range c.breakers.destinations {
+		snap[addr] = b.state
+	}
+	return snap
+}
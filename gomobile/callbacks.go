@@ -0,0 +1,110 @@
+package vyxclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ErrorCallback is an optional extension of Callback
+// If the Callback passed to NewClient also implements it, OnError receives
+// categorized errors such as "tls_cert_invalid".
+type ErrorCallback interface {
+	OnError(code string, message string)
+}
+
+// notifyError delivers an error to the callback if it implements ErrorCallback
+// Repeated errors are coalesced and rate limited, see limitError.
+func (c *Client) notifyError(code string, message string) {
+	c.observe(EventError, map[string]interface{}{"code": code, "message": message})
+	if cb, ok := c.callback.(ErrorCallback); ok {
+		c.limitError("error\x00"+code+"\x00"+message, func(repeats int) {
+			cb.OnError(code, withRepeats(message, repeats))
+		})
+	}
+}
+
+// AuthCallback is an optional extension of Callback
+// OnAuthenticating fires once the auth message has been sent.
+// OnAuthProgress fires for each "auth_progress" message of a multi-step
+// auth (challenge, MFA, ...); stage is the message ID, data its payload.
+type AuthCallback interface {
+	OnAuthenticating()
+	OnAuthProgress(stage string, data string)
+}
+
+// notifyAuthenticating tells the app authentication is in progress
+func (c *Client) notifyAuthenticating() {
+	if cb, ok := c.callback.(AuthCallback); ok {
+		cb.OnAuthenticating()
+	}
+}
+
+// notifyAuthProgress forwards an intermediate auth step
+func (c *Client) notifyAuthProgress(stage string, data string) {
+	if cb, ok := c.callback.(AuthCallback); ok {
+		cb.OnAuthProgress(stage, data)
+	}
+}
+
+// optionalCallback describes one optional extension of Callback
+type optionalCallback struct {
+	name        string
+	feature     string
+	implemented func(cb Callback) bool
+}
+
+// optionalCallbacks lists every optional extension of Callback
+// Add new extension interfaces here so CheckCallbackCapabilities reports them.
+var optionalCallbacks = []optionalCallback{
+	{"ErrorCallback", "categorized errors", func(cb Callback) bool { _, ok := cb.(ErrorCallback); return ok }},
+	{"AuthCallback", "auth progress", func(cb Callback) bool { _, ok := cb.(AuthCallback); return ok }},
+	{"AuthFailedCallback", "auth failure reasons", func(cb Callback) bool { _, ok := cb.(AuthFailedCallback); return ok }},
+	{"ConnectionOpenedCallback", "Go-side dial notifications", func(cb Callback) bool { _, ok := cb.(ConnectionOpenedCallback); return ok }},
+	{"ConnectionClosedCallback", "SDK-initiated close reasons", func(cb Callback) bool { _, ok := cb.(ConnectionClosedCallback); return ok }},
+	{"ConnectionRefusedCallback", "refused connection notifications", func(cb Callback) bool { _, ok := cb.(ConnectionRefusedCallback); return ok }},
+	{"ConnectionLivenessCallback", "per-connection liveness", func(cb Callback) bool { _, ok := cb.(ConnectionLivenessCallback); return ok }},
+	{"HealthCheckCallback", "health check results", func(cb Callback) bool { _, ok := cb.(HealthCheckCallback); return ok }},
+	{"FlapCallback", "flap detection", func(cb Callback) bool { _, ok := cb.(FlapCallback); return ok }},
+	{"ThrottleCallback", "server throttling", func(cb Callback) bool { _, ok := cb.(ThrottleCallback); return ok }},
+	{"StatsSnapshotCallback", "periodic stats snapshots", func(cb Callback) bool { _, ok := cb.(StatsSnapshotCallback); return ok }},
+	{"PathIssueCallback", "asymmetric path detection", func(cb Callback) bool { _, ok := cb.(PathIssueCallback); return ok }},
+	{"ReconnectScheduledCallback", "reconnect countdown", func(cb Callback) bool { _, ok := cb.(ReconnectScheduledCallback); return ok }},
+	{"ConnectedDetailsCallback", "negotiated TLS details", func(cb Callback) bool { _, ok := cb.(ConnectedDetailsCallback); return ok }},
+	{"TransportErrorCallback", "server close codes", func(cb Callback) bool { _, ok := cb.(TransportErrorCallback); return ok }},
+}
+
+// CheckCallbackCapabilities reports which optional callbacks are implemented
+// Returns JSON mapping each extension interface name to true or false, so
+// apps can verify their Callback is wired up at startup.
+func (c *Client) CheckCallbackCapabilities() string {
+	caps := make(map[string]bool, len(optionalCallbacks))
+	for _, oc := range optionalCallbacks {
+		caps[oc.name] = c.callback != nil && oc.implemented(c.callback)
+	}
+
+	data, err := json.Marshal(caps)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// logCallbackCapabilities logs once which optional callbacks will be used
+func (c *Client) logCallbackCapabilities() {
+	if c.callback == nil {
+		return
+	}
+
+	var implemented, missing []string
+	for _, oc := range optionalCallbacks {
+		if oc.implemented(c.callback) {
+			implemented = append(implemented, oc.name)
+		} else {
+			missing = append(missing, oc.feature)
+		}
+	}
+
+	c.log(fmt.Sprintf("INFO: optional callbacks implemented: [%s]; not available: [%s]",
+		strings.Join(implemented, ", "), strings.Join(missing, ", ")))
+}
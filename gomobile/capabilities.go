@@ -0,0 +1,95 @@
+package vyxclient
+
+import (
+	"sort"
+	"sync"
+)
+
+// Optional protocol features negotiated during authentication
+// The client lists what it's willing to use in the auth message "caps"; the
+// server answers with the subset it supports in auth_success "caps". A
+// feature is only used when both sides listed it.
+const (
+	// capConnectResultWithData sends a target's first bytes with the dial result
+	capConnectResultWithData = "connect_result_with_data"
+	// capConnStreams carries each tunneled connection on its own QUIC stream
+	capConnStreams = "conn_streams"
+	// capBinaryData sends data messages as binary frames
+	capBinaryData = "binary_data"
+	// capCompression deflate-compresses data payloads
+	capCompression = "deflate_data"
+	// capMetadataUpdate lets SetMetadata update the server mid-session
+	capMetadataUpdate = "metadata_update"
+)
+
+// negotiatedCaps holds the capabilities agreed for the current connection
+type negotiatedCaps struct {
+	mu   sync.Mutex
+	caps map[string]bool
+}
+
+// localCaps returns the capabilities to offer, based on the config
+func (c *Client) localCaps() []string {
+	cfg := c.getConfig()
+
+	caps := []string{capMetadataUpdate}
+	if cfg.ConnectResultWithData {
+		caps = append(caps, capConnectResultWithData)
+	}
+	if cfg.ConnectionStreams {
+		caps = append(caps, capConnStreams)
+	}
+	if cfg.BinaryData {
+		caps = append(caps, capBinaryData)
+	}
+	if cfg.Compression {
+		caps = append(caps, capCompression)
+	}
+	return caps
+}
+
+// setNegotiatedCaps records the server's answer, keeping only what was offered
+func (c *Client) setNegotiatedCaps(offered []string, accepted []string) {
+	offer := make(map[string]bool, len(offered))
+	for _, cp := range offered {
+		offer[cp] = true
+	}
+
+	caps := make(map[string]bool, len(accepted))
+	for _, cp := range accepted {
+		if offer[cp] {
+			caps[cp] = true
+		}
+	}
+
+	c.caps.mu.Lock()
+	c.caps.caps = caps
+	c.caps.mu.Unlock()
+}
+
+// clearNegotiatedCaps forgets the capabilities of a closed connection
+func (c *Client) clearNegotiatedCaps() {
+	c.caps.mu.Lock()
+	c.caps.caps = nil
+	c.caps.mu.Unlock()
+}
+
+// hasCap reports whether a capability was negotiated on this connection
+func (c *Client) hasCap(name string) bool {
+	c.caps.mu.Lock()
+	defer c.caps.mu.Unlock()
+	return c.caps.caps[name]
+}
+
+// negotiatedCapList returns the negotiated capabilities, sorted
+func (c *Client) negotiatedCapList() []string {
+	c.caps.mu.Lock()
+	defer c.caps.mu.Unlock()
+
+	list := make([]string, 0, len(c.caps.caps))
+	for cp := range c.caps.caps {
+		list = append(list, cp)
+	}
+	sort.Strings(list)
+	return list
+}
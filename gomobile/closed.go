@@ -0,0 +1,117 @@
+package vyxclient
+
+import (
+	"sync"
+	"time"
+)
+
+// closedRetention is how long a closed connection ID is remembered
+const closedRetention = 2 * time.Minute
+
+// closedPruneInterval spaces out sweeps for expired IDs, so closing many
+// connections at once doesn't rescan the map for each
+const closedPruneInterval = closedRetention / 4
+
+// closedConns remembers recently closed connection IDs so that a late or
+// duplicate "close" (client and server closing at the same time) is ignored
+// It also tracks the connections currently open in this session.
+type closedConns struct {
+	mu  sync.Mutex
+	ids map[string]time.Time
+	// prunedAt is when expired IDs were last swept
+	prunedAt time.Time
+	// open maps the connections open in this session to their target address
+	open map[string]string
+}
+
+// markClosed records id as closed
+// Returns false if it was already closed, i.e. this close is a duplicate.
+func (c *Client) markClosed(id string) bool {
+	now := time.Now()
+
+	c.closed.mu.Lock()
+	defer c.closed.mu.Unlock()
+
+	if c.closed.ids == nil {
+		c.closed.ids = make(map[string]time.Time)
+	}
+
+	if now.Sub(c.closed.prunedAt) > closedPruneInterval {
+		c.closed.prunedAt = now
+		for closedID, at := range c.closed.ids {
+			if now.Sub(at) > closedRetention {
+				delete(c.closed.ids, closedID)
+			}
+		}
+	}
+
+	// Entries past retention may linger until the next sweep
+	if at, dup := c.closed.ids[id]; dup && now.Sub(at) <= closedRetention {
+		return false
+	}
+	c.closed.ids[id] = now
+	delete(c.closed.open, id)
+	c.stats.connectionsClosed.Add(1)
+	c.observe(EventConnectionClosed, map[string]interface{}{"id": id})
+	return true
+}
+
+// trackOpen records a newly opened connection to addr
+func (c *Client) trackOpen(id string, addr string) {
+	c.closed.mu.Lock()
+	defer c.closed.mu.Unlock()
+
+	if c.closed.open == nil {
+		c.closed.open = make(map[string]string)
+	}
+	c.closed.open[id] = addr
+}
+
+// forgetOpen drops id from the open set without recording a close
+// Used when a connection is handed to another client.
+func (c *Client) forgetOpen(id string) {
+	c.closed.mu.Lock()
+	delete(c.closed.open, id)
+	c.closed.mu.Unlock()
+}
+
+// openConnections returns the open connections of this session and their addresses
+func (c *Client) openConnections() map[string]string {
+	c.closed.mu.Lock()
+	defer c.closed.mu.Unlock()
+
+	open := make(map[string]string, len(c.closed.open))
+	for id, addr := range c.closed.open {
+		open[id] = addr
+	}
+	return open
+}
+
+// isOpen reports whether id is open in this session
+func (c *Client) isOpen(id string) bool {
+	c.closed.mu.Lock()
+	defer c.closed.mu.Unlock()
+	_, ok := c.closed.open[id]
+	return ok
+}
+
+// openCount returns the number of connections open in this session
+func (c *Client) openCount() int {
+	c.closed.mu.Lock()
+	defer c.closed.mu.Unlock()
+	return len(c.closed.open)
+}
+
+// clearOpen forgets open connections when the session ends
+func (c *Client) clearOpen() {
+	c.closed.mu.Lock()
+	c.closed.open = nil
+	c.closed.mu.Unlock()
+}
+
+// unmarkClosed forgets id, used when the server reuses it for a new connection
+func (c *Client) unmarkClosed(id string) {
+	c.closed.mu.Lock()
+	delete(c.closed.ids, id)
+	c.closed.mu.Unlock()
+}
@@ -0,0 +1,134 @@
+package vyxclient
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// maxCoalesceBytes flushes the coalescing buffer once it grows this large
+const maxCoalesceBytes = 64 * 1024
+
+// coalescingWriter batches small writes made within a short window into one
+// stream write. Urgent writes flush the buffer (and themselves) immediately.
+// With a buffered write, errors surface on the next write or flush.
+type coalescingWriter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	delay time.Duration
+	// timeout bounds each write to w, 0 disables
+	timeout time.Duration
+	buf     []byte
+	timer   *time.Timer
+	err     error
+}
+
+// newCoalescingWriter wraps w, delay 0 disables coalescing
+func newCoalescingWriter(w io.Writer, delay time.Duration, timeout time.Duration) *coalescingWriter {
+	return &coalescingWriter{w: w, delay: delay, timeout: timeout}
+}
+
+// write queues p, flushing right away if urgent, disabled or the buffer is full
+func (cw *coalescingWriter) write(p []byte, urgent bool) error {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	if cw.err != nil {
+		return cw.err
+	}
+
+	if cw.delay <= 0 && len(cw.buf) == 0 {
+		return cw.writeAllLocked(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if urgent || cw.delay <= 0 || len(cw.buf) >= maxCoalesceBytes {
+		return cw.flushLocked()
+	}
+
+	if cw.timer == nil {
+		cw.timer = time.AfterFunc(cw.delay, cw.flush)
+	}
+	return nil
+}
+
+// flush writes out anything buffered
+func (cw *coalescingWriter) flush() {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	cw.flushLocked()
+}
+
+// flushCount writes out anything buffered and returns how many bytes it wrote
+func (cw *coalescingWriter) flushCount() int {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+
+	n := len(cw.buf)
+	if cw.flushLocked() != nil {
+		return 0
+	}
+	return n
+}
+
+// flushLocked writes the buffer, cw.mu must be held
+func (cw *coalescingWriter) flushLocked() error {
+	if cw.timer != nil {
+		cw.timer.Stop()
+		cw.timer = nil
+	}
+	if len(cw.buf) == 0 {
+		return cw.err
+	}
+
+	err := cw.writeAllLocked(cw.buf)
+	cw.buf = cw.buf[:0]
+	return err
+}
+
+// writeAllLocked writes p and records a failure for later writes
+func (cw *coalescingWriter) writeAllLocked(p []byte) error {
+	if err := writeFullTimeout(cw.w, p, cw.timeout); err != nil {
+		cw.err = err
+		return err
+	}
+	return nil
+}
+
+// writeFull writes all of p, retrying short writes
+// io.Writer requires an error with a short write, but not every transport
+// honors that; a writer that stops making progress yields io.ErrShortWrite.
+func writeFull(w io.Writer, p []byte) error {
+	for len(p) > 0 {
+		n, err := w.Write(p)
+		if err != nil {
+			return err
+		}
+		if n <= 0 {
+			return io.ErrShortWrite
+		}
+		p = p[n:]
+	}
+	return nil
+}
+
+// SetWriteCoalescing batches small control-stream writes made within
+// delayMillis of each other into a single write. Connects, closes, pings and
+// interactive data are never delayed. 0 (default) disables coalescing.
+// Applies from the next connection.
+func (c *Client) SetWriteCoalescing(delayMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.WriteCoalesceDelayMs = delayMillis
+	})
+}
+
+// isUrgent reports whether a message must be written without coalescing delay
+func isUrgent(msg *Message, priority int) bool {
+	switch msg.Type {
+	case "auth", "connect", "close", "ping":
+		return true
+	case "data":
+		return priority == PriorityInteractive
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package vyxclient
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// countingWriter counts Write calls, each of which is a syscall on a real stream
+type countingWriter struct {
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return len(p), nil
+}
+
+// BenchmarkCoalescingWriter compares stream writes for a burst of small
+// control messages with coalescing off and on
+func BenchmarkCoalescingWriter(b *testing.B) {
+	msg := []byte(`{"type":"pong","id":"0123456789abcdef"}` + "\n")
+	const burst = 32
+
+	for _, delay := range []time.Duration{0, time.Millisecond} {
+		b.Run(fmt.Sprintf("delay=%v", delay), func(b *testing.B) {
+			w := &countingWriter{}
+			cw := newCoalescingWriter(w, delay, 0)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for j := 0; j < burst; j++ {
+					cw.write(msg, false)
+				}
+				cw.flush()
+			}
+			b.ReportMetric(float64(w.writes)/float64(b.N*burst), "writes/msg")
+		})
+	}
+}
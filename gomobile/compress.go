@@ -0,0 +1,145 @@
+package vyxclient
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Data compression
+//
+// When the server accepts the deflate_data capability, data payloads of at
+// least CompressMinBytes are deflate-compressed (raw DEFLATE, RFC 1951) if
+// that makes them smaller. Compression happens before encryption and base64,
+// decompression after decryption. A compressed chunk is flagged with
+// "enc":"deflate" in JSON, or starts with binaryFrameDeflate instead of
+// binaryFrameMarker as a binary frame. Chunks are compressed independently,
+// so either side can mix compressed and plain chunks freely.
+
+// encDeflate flags a deflate-compressed data payload
+const encDeflate = "deflate"
+
+// reasonDecompressFailed is reported when a compressed payload can't be inflated
+const reasonDecompressFailed = "decompress_failed"
+
+// errDecompress means a payload flagged as compressed is corrupt or too large
+var errDecompress = errors.New("invalid compressed payload")
+
+// flateWriters reuses compressors, which are expensive to allocate
+var flateWriters = sync.Pool{
+	New: func() interface{} {
+		w, _ := flate.NewWriter(nil, flate.BestSpeed)
+		return w
+	},
+}
+
+// SetCompression offers deflate compression of data payloads
+// Payloads smaller than minBytes are sent as they are, since compressing
+// them costs more than it saves. Takes effect at the next authentication;
+// the server must accept the deflate_data capability.
+func (c *Client) SetCompression(enabled bool, minBytes int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.Compression = enabled
+		cfg.CompressMinBytes = minBytes
+	})
+}
+
+// compressPayload compresses a data payload if negotiated and worthwhile
+// Returns p itself and false if it's left uncompressed.
+func (c *Client) compressPayload(p []byte) ([]byte, bool) {
+	if !c.hasCap(capCompression) {
+		return p, false
+	}
+	c.stats.compressPlain.Add(int64(len(p)))
+	if len(p) < c.getConfig().CompressMinBytes {
+		c.stats.compressWire.Add(int64(len(p)))
+		return p, false
+	}
+
+	var buf bytes.Buffer
+	w := flateWriters.Get().(*flate.Writer)
+	w.Reset(&buf)
+	_, err := w.Write(p)
+	if err == nil {
+		err = w.Close()
+	}
+	flateWriters.Put(w)
+
+	if err != nil || buf.Len() >= len(p) {
+		c.stats.compressWire.Add(int64(len(p)))
+		return p, false
+	}
+	c.stats.compressWire.Add(int64(buf.Len()))
+	return buf.Bytes(), true
+}
+
+// compressData compresses a base64 data payload if negotiated and worthwhile
+// Returns the payload unchanged and false if it isn't compressed.
+func (c *Client) compressData(encoded string) (string, bool) {
+	if !c.hasCap(capCompression) {
+		return encoded, false
+	}
+	plain, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		// Reported by sealData if it matters
+		return encoded, false
+	}
+	compressed, ok := c.compressPayload(plain)
+	if !ok {
+		return encoded, false
+	}
+	return base64.StdEncoding.EncodeToString(compressed), true
+}
+
+// inflatePayload undoes compressPayload, bounded by maxBinaryFramePayload
+func inflatePayload(compressed []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(compressed))
+	defer r.Close()
+
+	plain, err := io.ReadAll(io.LimitReader(r, maxBinaryFramePayload+1))
+	if err != nil || len(plain) > maxBinaryFramePayload {
+		return nil, errDecompress
+	}
+	return plain, nil
+}
+
+// decompressPayload inflates a received payload according to its enc flag
+func decompressPayload(enc string, p []byte) ([]byte, error) {
+	switch enc {
+	case "":
+		return p, nil
+	case encDeflate:
+		return inflatePayload(p)
+	}
+	return nil, fmt.Errorf("unknown data encoding %q", enc)
+}
+
+// decompressData inflates a received base64 payload according to its enc flag
+func decompressData(enc string, encoded string) (string, error) {
+	if enc == "" {
+		return encoded, nil
+	}
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", errDecompress
+	}
+	plain, err := decompressPayload(enc, compressed)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(plain), nil
+}
+
+// compressionRatio returns plain over wire bytes of data sent while
+// compression was negotiated, 0 if none was
+func (c *Client) compressionRatio() float64 {
+	wire := c.stats.compressWire.Load()
+	if wire == 0 {
+		return 0
+	}
+	return float64(c.stats.compressPlain.Load()) / float64(wire)
+}
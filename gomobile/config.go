@@ -0,0 +1,199 @@
+package vyxclient
+
+// clientConfig holds the tunable settings of a Client
+// Setters copy-on-write this struct under configMutex; readers take a snapshot
+// via getConfig() so a setting never changes halfway through an operation.
+type clientConfig struct {
+	// LogSampleIntervalMs coalesces high-frequency log lines into one summary per interval
+	// 0 logs every event, negative suppresses them entirely
+	LogSampleIntervalMs int `json:"logSampleIntervalMs"`
+	// TokenProviderTimeoutMs bounds how long the TokenProvider may take
+	TokenProviderTimeoutMs int `json:"tokenProviderTimeoutMs"`
+	// MaxPendingRequests caps outstanding correlated requests, 0 means unlimited
+	MaxPendingRequests int `json:"maxPendingRequests"`
+	// LocalDialing makes the SDK dial "connect" targets itself
+	LocalDialing bool `json:"localDialing"`
+	// DialTimeoutMs is the per-address timeout of the Go-side dialer
+	DialTimeoutMs int `json:"dialTimeoutMs"`
+	// Health check probe settings, HealthCheckIntervalMs <= 0 disables
+	HealthCheckTarget     string `json:"healthCheckTarget"`
+	HealthCheckProbe      string `json:"healthCheckProbe"`
+	HealthCheckExpect     string `json:"healthCheckExpect"`
+	HealthCheckIntervalMs int    `json:"healthCheckIntervalMs"`
+	// DSCP marks the QUIC UDP packets, 0 leaves the default
+	DSCP int `json:"dscp"`
+	// MaxConnections caps Go-side relayed connections, 0 means unlimited
+	MaxConnections int `json:"maxConnections"`
+	// Bandwidth limits in bytes per second, 0 means unlimited
+	RateLimitBytesPerSec     int64 `json:"rateLimitBytesPerSec"`
+	RateLimitBurstBytes      int64 `json:"rateLimitBurstBytes"`
+	ConnRateLimitBytesPerSec int64 `json:"connRateLimitBytesPerSec"`
+	ConnRateLimitBurstBytes  int64 `json:"connRateLimitBurstBytes"`
+	// ReopenControlStream reopens a server-closed control stream on the live connection
+	ReopenControlStream bool `json:"reopenControlStream"`
+	// Flap protection, FlapCount 0 disables
+	FlapCount       int `json:"flapCount"`
+	FlapThresholdMs int `json:"flapThresholdMs"`
+	FlapWindowMs    int `json:"flapWindowMs"`
+	FlapCooldownMs  int `json:"flapCooldownMs"`
+	// WriteCoalesceDelayMs batches non-urgent control stream writes, 0 disables
+	WriteCoalesceDelayMs int `json:"writeCoalesceDelayMs"`
+	// DefaultConnectionTTLMs force-closes connections after this long, 0 disables
+	DefaultConnectionTTLMs int `json:"defaultConnectionTTLMs"`
+	// MaxConcurrentDials caps simultaneous Go-side dials
+	MaxConcurrentDials int `json:"maxConcurrentDials"`
+	// MessageTimestamps stamps outgoing messages with their send time
+	MessageTimestamps bool `json:"messageTimestamps"`
+	// Per-destination circuit breaker, BreakerFailures 0 disables
+	BreakerFailures   int `json:"breakerFailures"`
+	BreakerWindowMs   int `json:"breakerWindowMs"`
+	BreakerCooldownMs int `json:"breakerCooldownMs"`
+	// NetworkType selects the QUIC idle timeout and keepalive profile
+	NetworkType string `json:"networkType"`
+	// Explicit QUIC timers, 0 uses the network profile's value
+	QUICIdleTimeoutMs     int `json:"quicIdleTimeoutMs"`
+	QUICKeepAlivePeriodMs int `json:"quicKeepAlivePeriodMs"`
+	// StatsSnapshotIntervalMs emits OnStatsSnapshot this often, <= 0 disables
+	StatsSnapshotIntervalMs int `json:"statsSnapshotIntervalMs"`
+	// WarmPool maps destinations to the number of idle connections kept open
+	WarmPool              map[string]int `json:"warmPool,omitempty"`
+	WarmPoolIdleTimeoutMs int            `json:"warmPoolIdleTimeoutMs"`
+	// CallbackWorkers delivers OnMessage on this many workers, 0 delivers inline
+	CallbackWorkers int `json:"callbackWorkers"`
+	// Server hostname resolution: "system", "doh" or "dot"
+	ResolverMode     string `json:"resolverMode"`
+	ResolverServer   string `json:"resolverServer,omitempty"`
+	ResolverFallback bool   `json:"resolverFallback"`
+	// TCP keepalive on Go-side dialed connections, period 0 uses the Go default
+	TCPKeepAlive         bool `json:"tcpKeepAlive"`
+	TCPKeepAlivePeriodMs int  `json:"tcpKeepAlivePeriodMs"`
+	// ConnectResultWithData offers the connect_result_with_data capability
+	ConnectResultWithData bool `json:"connectResultWithData"`
+	InitialDataWaitMs     int  `json:"initialDataWaitMs"`
+	// MaxConnectionLifetimeMs reconnects for fresh keys after this long, 0 disables
+	MaxConnectionLifetimeMs int `json:"maxConnectionLifetimeMs"`
+	LifetimeDrainMs         int `json:"lifetimeDrainMs"`
+	// StreamOpenTimeoutMs bounds waiting for a stream slot
+	StreamOpenTimeoutMs int `json:"streamOpenTimeoutMs"`
+	// RedirectDrainMs bounds flushing and confirming sends before a redirect
+	RedirectDrainMs int `json:"redirectDrainMs"`
+	// RelayMode is "goroutines" or "pool", RelayWorkers 0 picks a default
+	RelayMode    string `json:"relayMode"`
+	RelayWorkers int    `json:"relayWorkers"`
+	// Reconnection backoff, see ReconnectPolicy
+	ReconnectInitialDelayMs int     `json:"reconnectInitialDelayMs"`
+	ReconnectMaxDelayMs     int     `json:"reconnectMaxDelayMs"`
+	ReconnectMultiplier     float64 `json:"reconnectMultiplier"`
+	ReconnectJitter         float64 `json:"reconnectJitter"`
+	// ReconnectStableMs is how long a connection must last to reset the backoff
+	ReconnectStableMs int `json:"reconnectStableMs"`
+	// ZeroRTT sends the auth message as 0-RTT data when resuming
+	ZeroRTT bool `json:"zeroRTT"`
+	// ConnectionMigration lets MigrateConnection move the connection to a new path
+	ConnectionMigration bool `json:"connectionMigration"`
+	// ConnectTimeoutMs bounds each attempt to connect and authenticate, 0 disables
+	ConnectTimeoutMs int `json:"connectTimeoutMs"`
+	// Compression offers the deflate_data capability for data payloads of
+	// at least CompressMinBytes
+	Compression      bool `json:"compression"`
+	CompressMinBytes int  `json:"compressMinBytes"`
+	// RelayBufferBytes is the size of the relays' read buffers
+	RelayBufferBytes int `json:"relayBufferBytes"`
+	// PathCheckIntervalMs runs directional path checks this often, <= 0 disables
+	PathCheckIntervalMs int `json:"pathCheckIntervalMs"`
+	// PinnedCertSHA256 is the hex SHA-256 of the server's leaf certificate, empty disables
+	PinnedCertSHA256 string `json:"pinnedCertSHA256,omitempty"`
+	// HistogramsEnabled records the distributions returned by GetHistograms
+	HistogramsEnabled bool `json:"histogramsEnabled"`
+	// Error callback limits: identical errors are coalesced within the window
+	// and at most MaxErrorsPerSec are delivered, 0 disables either
+	ErrorDedupeWindowMs int `json:"errorDedupeWindowMs"`
+	MaxErrorsPerSec     int `json:"maxErrorsPerSec"`
+	// Per-connection buffer budget for data towards targets, 0 is unlimited
+	ConnBufferBytes  int64  `json:"connBufferBytes"`
+	ConnBufferPolicy string `json:"connBufferPolicy"`
+	// ConnectionStreams offers the conn_streams capability
+	ConnectionStreams bool `json:"connectionStreams"`
+	// LogLevel is the most verbose level logged (LogLevelOff..LogLevelDebug)
+	LogLevel int `json:"logLevel"`
+	// SystemLog also writes log lines to Go's log package
+	SystemLog bool `json:"systemLog"`
+	// ConnIdleTimeoutMs closes Go-side connections without traffic, 0 disables
+	ConnIdleTimeoutMs int `json:"connIdleTimeoutMs"`
+	// AuthTimeoutMs bounds the wait for each authentication response
+	AuthTimeoutMs int `json:"authTimeoutMs"`
+	// LocalAddr binds the QUIC socket to an IP, IP:port or interface
+	LocalAddr string `json:"localAddr"`
+	// WatchdogTimeoutMs reconnects after this long without server messages, 0 disables
+	WatchdogTimeoutMs int `json:"watchdogTimeoutMs"`
+	// BinaryData offers the binary_data capability
+	BinaryData bool `json:"binaryData"`
+	// ServerQuarantineMs skips a server failing authentication for this long, 0 disables
+	ServerQuarantineMs int `json:"serverQuarantineMs"`
+	// TLSCipherSuites lists the TLS 1.3 suites a server may choose, empty allows all
+	TLSCipherSuites string `json:"tlsCipherSuites,omitempty"`
+	// WriteTimeoutMs fails writes to a stream the server stopped reading, 0 disables
+	WriteTimeoutMs int `json:"writeTimeoutMs"`
+}
+
+// defaultConfig returns the settings used by NewClient
+func defaultConfig() clientConfig {
+	return clientConfig{
+		LogSampleIntervalMs:     5000,
+		TokenProviderTimeoutMs:  5000,
+		MaxPendingRequests:      64,
+		DialTimeoutMs:           10000,
+		ReopenControlStream:     true,
+		FlapCount:               5,
+		FlapThresholdMs:         3000,
+		FlapWindowMs:            60000,
+		FlapCooldownMs:          300000,
+		MaxConcurrentDials:      16,
+		BreakerFailures:         5,
+		BreakerWindowMs:         60000,
+		BreakerCooldownMs:       30000,
+		NetworkType:             NetworkTypeUnknown,
+		WarmPoolIdleTimeoutMs:   30000,
+		ResolverMode:            ResolverSystem,
+		TCPKeepAlive:            true,
+		InitialDataWaitMs:       50,
+		LifetimeDrainMs:         30000,
+		StreamOpenTimeoutMs:     10000,
+		RedirectDrainMs:         5000,
+		RelayMode:               RelayModeGoroutines,
+		ReconnectInitialDelayMs: 1000,
+		ReconnectMaxDelayMs:     64000,
+		ReconnectMultiplier:     2,
+		ErrorDedupeWindowMs:     5000,
+		MaxErrorsPerSec:         10,
+		ConnBufferBytes:         4 << 20,
+		ConnBufferPolicy:        BufferPolicyClose,
+		ConnectionStreams:       true,
+		LogLevel:                LogLevelInfo,
+		SystemLog:               true,
+		ConnIdleTimeoutMs:       300000,
+		AuthTimeoutMs:           10000,
+		BinaryData:              true,
+		ServerQuarantineMs:      300000,
+		WriteTimeoutMs:          10000,
+		ReconnectStableMs:       30000,
+		ConnectTimeoutMs:        15000,
+		CompressMinBytes:        512,
+		RelayBufferBytes:        32768,
+		ConnectionMigration:     true,
+	}
+}
+
+// getConfig returns a snapshot of the current settings
+func (c *Client) getConfig() clientConfig {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+	return c.config
+}
+
+// updateConfig applies fn to the settings under lock
+func (c *Client) updateConfig(fn func(cfg *clientConfig)) {
+	c.configMutex.Lock()
+	defer c.configMutex.Unlock()
+	fn(&c.config)
+}
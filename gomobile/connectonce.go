@@ -0,0 +1,78 @@
+package vyxclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ConnectOnce makes a single connect and authenticate attempt and waits for it
+// Unlike Start it doesn't retry or reconnect, so onboarding UIs can block on
+// the outcome. The error says why the attempt failed, prefixed with a reason:
+// "auth_failed" (bad token), "tls_cert_invalid", "tls_pin_mismatch",
+// "connect_failed" (network) or "timeout". A half-open connection is torn
+// down when the timeout expires; SetConnectTimeout applies too, whichever is
+// shorter. Calling Start afterwards adopts the connection and adds
+// auto-reconnect; OnConnected fires then. Don't call it while Start's loop
+// is running.
+// Returns error message or empty string on success
+func (c *Client) ConnectOnce(timeoutMillis int) string {
+	if c.IsConnected() {
+		return "already connected"
+	}
+	if timeoutMillis <= 0 {
+		return "timeout must be positive"
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, time.Duration(timeoutMillis)*time.Millisecond)
+	defer cancel()
+
+	c.observe(EventConnecting, map[string]interface{}{"attempt": 1, "server": c.currentServer()})
+	err := c.connectAttempt(ctx)
+	if err == nil {
+		c.stats.connects.Add(1)
+		c.stats.lastConnectedMs.Store(nowMillis())
+		c.observe(EventConnected, map[string]interface{}{"server": c.currentServer()})
+		return ""
+	}
+
+	c.stats.connectFailures.Add(1)
+	reason := classifyConnectError(err)
+	if errors.Is(err, context.DeadlineExceeded) {
+		reason = "timeout"
+		if ctx.Err() != nil {
+			err = fmt.Errorf("no connection within %dms", timeoutMillis)
+		}
+	}
+	c.observe(EventConnectFailed, map[string]interface{}{"reason": reason, "error": err.Error()})
+	return fmt.Sprintf("%s: %v", reason, err)
+}
+
+// SetConnectTimeout bounds each connection attempt to the server
+// An attempt that hasn't resolved, dialed and authenticated within
+// timeoutMillis is torn down and counted as a failure, so a black-holed
+// server can't stall reconnection. 0 disables; default 15s.
+func (c *Client) SetConnectTimeout(timeoutMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.ConnectTimeoutMs = timeoutMillis
+	})
+}
+
+// connectAttempt is connectWithContext bounded by the connect timeout
+func (c *Client) connectAttempt(ctx context.Context) error {
+	timeout := time.Duration(c.getConfig().ConnectTimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		return c.connectWithContext(ctx)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := c.connectWithContext(attemptCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		c.logError(fmt.Sprintf("Connection attempt timed out after %v", timeout))
+		return fmt.Errorf("connection attempt timed out after %v: %w", timeout, context.DeadlineExceeded)
+	}
+	return err
+}
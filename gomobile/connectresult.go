@@ -0,0 +1,91 @@
+package vyxclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+// connectResult is the payload of a "connect_result" message
+// With the connect_result_with_data capability, a successful Go-side dial is
+// reported as "connect_result_with_data" instead, carrying the first bytes
+// the target sent (base64) in Data so they needn't wait for a "data" message.
+type connectResult struct {
+	Success bool   `json:"success"`
+	DialMs  int    `json:"dialMs"`
+	Error   string `json:"error,omitempty"`
+	Data    string `json:"data,omitempty"`
+}
+
+// SetConnectResultWithData offers the connect_result_with_data capability
+// When the server accepts it, a Go-side dial waits up to waitMillis for the
+// target's first bytes (e.g. a server greeting) and sends them together with
+// the dial result, saving a round trip for protocols where the target speaks
+// first. Takes effect from the next connection.
+func (c *Client) SetConnectResultWithData(enabled bool, waitMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.ConnectResultWithData = enabled
+		cfg.InitialDataWaitMs = waitMillis
+	})
+}
+
+// ConfirmConnection reports the outcome of a "connect" handled by the app
+// Sends "connect_result" with the dial latency so the server knows when to
+// start routing data. On failure the app should still send "close".
+// Returns error message or empty string on success
+func (c *Client) ConfirmConnection(id string, success bool, dialMs int) string {
+	c.breakerRecord(c.takePendingConnect(id), success)
+
+	if err := c.sendConnectResult(id, "", connectResult{Success: success, DialMs: dialMs}); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// sendConnectResult sends a "connect_result" for a connection
+// Legacy servers only understand "connected", sent on success.
+func (c *Client) sendConnectResult(id string, addr string, result connectResult) error {
+	if c.GetProtocolVersion() == protocolLegacy {
+		if !result.Success {
+			return nil // the "close" that follows tells the server
+		}
+		if err := c.sendMessage(&Message{Type: "connected", ID: id, Addr: addr}); err != nil {
+			return err
+		}
+		if result.Data == "" {
+			return nil
+		}
+		return c.sendMessage(&Message{Type: "data", ID: id, Data: result.Data})
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal connect result: %w", err)
+	}
+	msgType := "connect_result"
+	if result.Data != "" {
+		msgType = "connect_result_with_data"
+	}
+	return c.sendMessage(&Message{Type: msgType, ID: id, Addr: addr, Data: string(data)})
+}
+
+// readInitialData reads whatever a freshly dialed target sends within wait
+// Returns nil if it sent nothing in time.
+func readInitialData(conn net.Conn, wait time.Duration) []byte {
+	if wait <= 0 {
+		return nil
+	}
+
+	buffer := make([]byte, 32768)
+	conn.SetReadDeadline(time.Now().Add(wait))
+	n, _ := conn.Read(buffer)
+	conn.SetReadDeadline(time.Time{})
+	// A read error other than the timeout resurfaces in the relay
+	return buffer[:n]
+}
+
+// dialMillis returns the elapsed time since start in milliseconds
+func dialMillis(start time.Time) int {
+	return int(time.Since(start).Milliseconds())
+}
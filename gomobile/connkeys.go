@@ -0,0 +1,155 @@
+package vyxclient
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// reasonDecryptFailed closes a connection whose data failed to decrypt
+const reasonDecryptFailed = "decrypt_failed"
+
+// errPayloadDecrypt is returned when a connection's data fails authentication
+var errPayloadDecrypt = errors.New("failed to decrypt connection data")
+
+// connectionKeys holds the payload cipher of each encrypted connection
+// Connections with a key carry AES-GCM sealed "data" payloads: the base64
+// "data" field holds nonce || ciphertext, with the connection ID as
+// additional data so a payload can't be replayed onto another connection.
+// Both ends of the connection must use the same key; the server only relays
+// the opaque payload, so tenants sharing a tunnel stay isolated.
+type connectionKeys struct {
+	mu    sync.RWMutex
+	aeads map[string]cipher.AEAD
+}
+
+// SetConnectionKey encrypts the data of connection id with its own key
+// keyBase64 is a base64 AES key of 16, 24 or 32 bytes; empty removes the key.
+// Set it before any data flows, e.g. right after OpenConnection or on
+// "connect". Applies to data sent and received for that connection only.
+// Returns error message or empty string on success
+func (c *Client) SetConnectionKey(id string, keyBase64 string) string {
+	if keyBase64 == "" {
+		c.forgetConnectionKey(id)
+		return ""
+	}
+
+	key, err := base64.StdEncoding.DecodeString(keyBase64)
+	if err != nil {
+		return fmt.Sprintf("invalid key encoding: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Sprintf("invalid key: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err.Error()
+	}
+
+	c.connKeys.mu.Lock()
+	defer c.connKeys.mu.Unlock()
+	if c.connKeys.aeads == nil {
+		c.connKeys.aeads = make(map[string]cipher.AEAD)
+	}
+	c.connKeys.aeads[id] = aead
+	return ""
+}
+
+// forgetConnectionKey drops the key of a closed connection
+func (c *Client) forgetConnectionKey(id string) {
+	c.connKeys.mu.Lock()
+	delete(c.connKeys.aeads, id)
+	c.connKeys.mu.Unlock()
+}
+
+// connectionAEAD returns the cipher of a connection, nil if unencrypted
+func (c *Client) connectionAEAD(id string) cipher.AEAD {
+	c.connKeys.mu.RLock()
+	defer c.connKeys.mu.RUnlock()
+	return c.connKeys.aeads[id]
+}
+
+// sealData encrypts a base64 data payload for connection id
+// Returns the payload unchanged if the connection has no key.
+func (c *Client) sealData(id string, encoded string) (string, error) {
+	aead := c.connectionAEAD(id)
+	if aead == nil {
+		return encoded, nil
+	}
+
+	plaintext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid data encoding: %w", err)
+	}
+	sealed, err := sealPayload(aead, id, plaintext)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// sealBytes encrypts a raw data payload for connection id
+// Returns the payload unchanged if the connection has no key.
+func (c *Client) sealBytes(id string, plaintext []byte) ([]byte, error) {
+	aead := c.connectionAEAD(id)
+	if aead == nil {
+		return plaintext, nil
+	}
+	return sealPayload(aead, id, plaintext)
+}
+
+// sealPayload encrypts plaintext under a fresh nonce, which it's prefixed with
+func sealPayload(aead cipher.AEAD, id string, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, []byte(id)), nil
+}
+
+// openData decrypts a base64 data payload received for connection id
+// Returns the payload unchanged if the connection has no key.
+func (c *Client) openData(id string, encoded string) (string, error) {
+	aead := c.connectionAEAD(id)
+	if aead == nil {
+		return encoded, nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", errPayloadDecrypt
+	}
+	plaintext, err := openPayload(aead, id, sealed)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(plaintext), nil
+}
+
+// openBytes decrypts a raw data payload received for connection id
+// Returns the payload unchanged if the connection has no key.
+func (c *Client) openBytes(id string, sealed []byte) ([]byte, error) {
+	aead := c.connectionAEAD(id)
+	if aead == nil {
+		return sealed, nil
+	}
+	return openPayload(aead, id, sealed)
+}
+
+// openPayload decrypts a payload produced by sealPayload
+func openPayload(aead cipher.AEAD, id string, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errPayloadDecrypt
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return nil, errPayloadDecrypt
+	}
+	return plaintext, nil
+}
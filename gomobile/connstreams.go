@@ -0,0 +1,276 @@
+package vyxclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// Per-connection streams
+//
+// When the server accepts the conn_streams capability, each tunneled
+// connection gets its own bidirectional QUIC stream, so a stalled or lossy
+// connection no longer blocks the others behind it. The control stream keeps
+// auth, ping/pong and everything without a connection ID. Either side opens a
+// connection's stream when it first sends a routed message for it; the first
+// frame on the stream is {"type":"stream_bind","id":"<id>"} and the rest use
+// the usual newline-delimited JSON. A connection whose stream can't be opened
+// stays on the control stream. FIN on a connection's stream half-closes it,
+// see halfclose.go.
+
+// streamRoutedTypes are message types carried on a connection's own stream
+var streamRoutedTypes = map[string]bool{
+	"connect":                  true,
+	"connected":                true,
+	"connect_result":           true,
+	"connect_result_with_data": true,
+	"data":                     true,
+	"close":                    true,
+	"conn_ping":                true,
+	"conn_resume":              true,
+}
+
+// connStreams maps connection IDs to their streams and back
+type connStreams struct {
+	mu       sync.Mutex
+	byID     map[string]*connStream
+	byStream map[quic.StreamID]string
+}
+
+// connStream is the stream carrying one connection
+type connStream struct {
+	id string
+	// stream is nil while opening and if opening failed
+	stream *quic.Stream
+	mu     sync.Mutex // serializes writes
+	ready  chan struct{}
+}
+
+// write sends an encoded message on the stream, giving up after timeout
+func (cs *connStream) write(data []byte, timeout time.Duration) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return writeFullTimeout(cs.stream, data, timeout)
+}
+
+// SetConnectionStreams offers carrying each connection on its own QUIC stream
+// Takes effect at the next authentication; the server must accept the
+// conn_streams capability.
+func (c *Client) SetConnectionStreams(enabled bool) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.ConnectionStreams = enabled
+	})
+}
+
+// connStreamFor returns the stream a message should be written to
+// Returns nil for the control stream. The stream is opened on the first
+// routed message for a connection, except "close".
+func (c *Client) connStreamFor(msg *Message) *connStream {
+	if msg.ID == "" || !streamRoutedTypes[msg.Type] || !c.hasCap(capConnStreams) {
+		return nil
+	}
+
+	s := &c.connStreams
+	s.mu.Lock()
+	cs, ok := s.byID[msg.ID]
+	if !ok {
+		if msg.Type == "close" {
+			s.mu.Unlock()
+			return nil
+		}
+		cs = &connStream{id: msg.ID, ready: make(chan struct{})}
+		if s.byID == nil {
+			s.byID = make(map[string]*connStream)
+		}
+		s.byID[msg.ID] = cs
+	}
+	s.mu.Unlock()
+
+	if ok {
+		<-cs.ready
+	} else {
+		c.openConnStream(cs)
+	}
+	if cs.stream == nil {
+		return nil
+	}
+	return cs
+}
+
+// openConnStream opens and binds the stream for a connection
+// On failure the connection keeps using the control stream.
+func (c *Client) openConnStream(cs *connStream) {
+	defer close(cs.ready)
+
+	c.quicMutex.Lock()
+	conn := c.quicConn
+	c.quicMutex.Unlock()
+	if conn == nil {
+		return
+	}
+
+	stream, err := c.openStream(conn)
+	if err != nil {
+		c.logDebug(fmt.Sprintf("Failed to open stream for %s, using control stream: %v", cs.id, err))
+		return
+	}
+	bind, _ := json.Marshal(&Message{Type: "stream_bind", ID: cs.id})
+	if _, err := stream.Write(append(bind, '\n')); err != nil {
+		c.logDebug(fmt.Sprintf("Failed to bind stream for %s, using control stream: %v", cs.id, err))
+		stream.CancelRead(0)
+		stream.Close()
+		return
+	}
+
+	s := &c.connStreams
+	s.mu.Lock()
+	if s.byID[cs.id] != cs {
+		// The connection ended while its stream was opening
+		s.mu.Unlock()
+		stream.CancelRead(0)
+		stream.Close()
+		return
+	}
+	cs.stream = stream
+	if s.byStream == nil {
+		s.byStream = make(map[quic.StreamID]string)
+	}
+	s.byStream[stream.StreamID()] = cs.id
+	s.mu.Unlock()
+
+	go c.readConnStream(cs.id, c.streamReader(stream, c.newStreamDecoder(stream)), stream)
+}
+
+// acceptConnStreams binds streams the server opens for its connections
+func (c *Client) acceptConnStreams(conn *quic.Conn) {
+	for {
+		stream, err := conn.AcceptStream(conn.Context())
+		if err != nil {
+			return
+		}
+		go c.bindAcceptedStream(stream)
+	}
+}
+
+// bindAcceptedStream reads the stream_bind frame of a server-opened stream
+func (c *Client) bindAcceptedStream(stream *quic.Stream) {
+	decoder := c.newStreamDecoder(stream)
+	var bind Message
+	if err := decoder.Decode(&bind); err != nil || bind.Type != "stream_bind" || bind.ID == "" {
+		c.log("Rejecting server stream without stream_bind")
+		stream.CancelRead(0)
+		stream.Close()
+		return
+	}
+
+	cs := &connStream{id: bind.ID, stream: stream, ready: make(chan struct{})}
+	close(cs.ready)
+
+	s := &c.connStreams
+	s.mu.Lock()
+	if s.byID == nil {
+		s.byID = make(map[string]*connStream)
+	}
+	if _, exists := s.byID[bind.ID]; !exists {
+		// Replies go on the server's stream unless we opened our own first
+		s.byID[bind.ID] = cs
+	}
+	if s.byStream == nil {
+		s.byStream = make(map[quic.StreamID]string)
+	}
+	s.byStream[stream.StreamID()] = bind.ID
+	s.mu.Unlock()
+
+	c.readConnStream(bind.ID, c.streamReader(stream, decoder), stream)
+}
+
+// readConnStream handles messages from a connection's stream until it ends
+func (c *Client) readConnStream(id string, reader messageReader, stream *quic.Stream) {
+	defer c.forgetConnStream(stream)
+
+	for c.shouldRun.Load() {
+		var msg Message
+		if err := reader.next(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				c.peerFinished(id)
+			}
+			return
+		}
+		if msg.ID == "" {
+			msg.ID = id
+		} else if msg.ID != id {
+			c.logSampled("stream-id-mismatch", fmt.Sprintf("Dropping %s for %s on stream bound to %s", msg.Type, msg.ID, id))
+			continue
+		}
+		c.receiveMessage(&msg)
+	}
+}
+
+// forgetConnStream removes a stream that stopped delivering
+func (c *Client) forgetConnStream(stream *quic.Stream) {
+	s := &c.connStreams
+	s.mu.Lock()
+	delete(s.byStream, stream.StreamID())
+	s.mu.Unlock()
+}
+
+// closeConnStream closes the stream of an ended connection
+func (c *Client) closeConnStream(id string) {
+	s := &c.connStreams
+	s.mu.Lock()
+	cs, ok := s.byID[id]
+	delete(s.byID, id)
+	s.mu.Unlock()
+
+	if ok {
+		go func() {
+			<-cs.ready
+			if cs.stream != nil {
+				cs.mu.Lock()
+				cs.stream.Close()
+				cs.mu.Unlock()
+				cs.stream.CancelRead(0)
+			}
+		}()
+	}
+}
+
+// dropConnStream abandons the stream of a connection without waiting on writes
+// Later messages for the connection, like its close, use the control stream.
+func (c *Client) dropConnStream(id string) {
+	s := &c.connStreams
+	s.mu.Lock()
+	cs, ok := s.byID[id]
+	delete(s.byID, id)
+	if ok && cs.stream != nil {
+		delete(s.byStream, cs.stream.StreamID())
+	}
+	s.mu.Unlock()
+
+	if ok && cs.stream != nil {
+		cs.stream.CancelWrite(0)
+		cs.stream.CancelRead(0)
+	}
+}
+
+// clearConnStreams forgets all connection streams when the QUIC connection ends
+func (c *Client) clearConnStreams() {
+	s := &c.connStreams
+	s.mu.Lock()
+	s.byID = nil
+	s.byStream = nil
+	s.mu.Unlock()
+}
+
+// connStreamCount returns how many connection streams are delivering
+func (c *Client) connStreamCount() int {
+	s := &c.connStreams
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.byStream)
+}
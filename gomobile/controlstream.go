@@ -0,0 +1,53 @@
+package vyxclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/quic-go/quic-go"
+)
+
+// SetReopenControlStream controls what happens when the server closes the
+// control stream (FIN) while the QUIC connection is still open.
+// true (default): open a new stream on the same connection and re-authenticate.
+// false: treat it like a lost connection and reconnect from scratch.
+// Tunneled connections are closed either way, since they belong to the old stream.
+func (c *Client) SetReopenControlStream(enabled bool) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.ReopenControlStream = enabled
+	})
+}
+
+// reopenControlStream opens and authenticates a new control stream on the current connection
+func (c *Client) reopenControlStream() (*quic.Stream, *json.Decoder, error) {
+	c.quicMutex.Lock()
+	conn := c.quicConn
+	c.quicMutex.Unlock()
+
+	if conn == nil {
+		return nil, nil, errors.New("no active QUIC connection")
+	}
+	if conn.Context().Err() != nil {
+		return nil, nil, errors.New("QUIC connection is closed")
+	}
+
+	c.log("Control stream closed by server, reopening on existing connection")
+
+	stream, err := c.openStream(conn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open stream: %w", err)
+	}
+
+	c.setControlStream(stream)
+
+	decoder := c.newStreamDecoder(stream)
+	if err := c.authenticate(stream, decoder); err != nil {
+		stream.CancelRead(0)
+		stream.Close()
+		return nil, nil, err
+	}
+
+	c.log("Control stream reopened")
+	return stream, decoder, nil
+}
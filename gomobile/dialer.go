@@ -0,0 +1,225 @@
+package vyxclient
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// ConnectionOpenedCallback is an optional extension of Callback
+// OnConnectionOpened fires when the Go-side dialer connects to a target;
+// addr is the address that succeeded when several candidates were given.
+type ConnectionOpenedCallback interface {
+	OnConnectionOpened(id string, addr string)
+}
+
+// SetLocalDialing makes the SDK dial "connect" targets itself instead of
+// forwarding them to OnMessage. Dialed connections are relayed entirely in Go.
+func (c *Client) SetLocalDialing(enabled bool) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.LocalDialing = enabled
+	})
+}
+
+// SetDialTimeout sets the timeout for each address tried by the Go-side dialer
+func (c *Client) SetDialTimeout(timeoutMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.DialTimeoutMs = timeoutMillis
+	})
+}
+
+// SetTCPKeepAlive configures OS-level TCP keepalive on Go-side dialed
+// connections, so targets that die silently are detected while idle.
+// periodMillis 0 uses the Go default (15s). Enabled by default.
+func (c *Client) SetTCPKeepAlive(enabled bool, periodMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.TCPKeepAlive = enabled
+		cfg.TCPKeepAlivePeriodMs = periodMillis
+	})
+}
+
+// applyTCPKeepAlive sets keepalive on a dialed connection per the config
+func applyTCPKeepAlive(conn net.Conn, cfg clientConfig) error {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+	if err := tcpConn.SetKeepAlive(cfg.TCPKeepAlive); err != nil {
+		return err
+	}
+	if cfg.TCPKeepAlive && cfg.TCPKeepAlivePeriodMs > 0 {
+		return tcpConn.SetKeepAlivePeriod(time.Duration(cfg.TCPKeepAlivePeriodMs) * time.Millisecond)
+	}
+	return nil
+}
+
+// splitAddrs parses a comma-separated list of candidate addresses
+func splitAddrs(addrs string) []string {
+	parts := strings.Split(addrs, ",")
+	result := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// dialTarget tries each candidate address in order until one connects
+// Returns the connection and the address that succeeded.
+func (c *Client) dialTarget(addrs string) (net.Conn, string, error) {
+	candidates := splitAddrs(addrs)
+	if len(candidates) == 0 {
+		return nil, "", errors.New("no target address")
+	}
+
+	cfg := c.getConfig()
+	timeout := time.Duration(cfg.DialTimeoutMs) * time.Millisecond
+	// Keepalive is applied per connection by applyTCPKeepAlive
+	dialer := &net.Dialer{KeepAlive: -1}
+
+	var lastErr error
+	for _, addr := range candidates {
+		ctx, cancel := context.WithTimeout(c.ctx, timeout)
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		cancel()
+		if err == nil {
+			if err := applyTCPKeepAlive(conn, cfg); err != nil {
+				c.log(fmt.Sprintf("Failed to set TCP keepalive on %s: %v", addr, err))
+			}
+			return conn, addr, nil
+		}
+		c.logDebug(fmt.Sprintf("Dial %s failed: %v", addr, err))
+		lastErr = err
+	}
+	return nil, "", lastErr
+}
+
+// handleLocalConnect dials the target of a server "connect" and starts relaying
+func (c *Client) handleLocalConnect(id string, addrs string, priority int) {
+	release, err := c.acquireEstablishSlot(c.ctx, priority)
+	if err != nil {
+		return
+	}
+	defer release()
+
+	start := time.Now()
+	conn, addr, err := c.dialTarget(addrs)
+	c.breakerRecord(addrs, err == nil)
+	if err != nil {
+		c.log(fmt.Sprintf("Failed to open connection %s: %v", id, err))
+		c.sendConnectResult(id, "", connectResult{Success: false, DialMs: dialMillis(start), Error: err.Error()})
+		c.sendMessage(&Message{Type: "close", ID: id})
+		c.markClosed(id)
+		return
+	}
+
+	result := connectResult{Success: true, DialMs: dialMillis(start)}
+	if c.hasCap(capConnectResultWithData) {
+		wait := time.Duration(c.getConfig().InitialDataWaitMs) * time.Millisecond
+		if initial := readInitialData(conn, wait); len(initial) > 0 {
+			result.Data = base64.StdEncoding.EncodeToString(initial)
+		}
+		// The result goes out before the relay can send any "data"
+		c.sendConnectResult(id, addr, result)
+		c.registerConnection(id, conn)
+	} else {
+		c.registerConnection(id, conn)
+		c.sendConnectResult(id, addr, result)
+	}
+
+	if cb, ok := c.callback.(ConnectionOpenedCallback); ok {
+		cb.OnConnectionOpened(id, addr)
+	}
+}
+
+// deliverData queues server data for a registered connection
+// Returns false if the connection isn't relayed by the SDK.
+func (c *Client) deliverData(id string, encoded string) bool {
+	if !c.isRelayed(id) {
+		return false
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		c.log(fmt.Sprintf("Invalid data for connection %s: %v", id, err))
+		c.closeConnection(id, true)
+		return true
+	}
+	return c.deliverBytes(id, data)
+}
+
+// deliverBytes queues decoded server data for a connection relayed by the SDK
+// Returns false if the connection isn't relayed by the SDK.
+func (c *Client) deliverBytes(id string, data []byte) bool {
+	if !c.isRelayed(id) {
+		return false
+	}
+	if err := c.enqueueData(id, data); errors.Is(err, errBufferFull) {
+		c.log(fmt.Sprintf("Connection %s buffer full, closing", id))
+		c.closeWithReason(id, reasonBufferFull)
+	}
+	return true
+}
+
+// WriteData queues data for the target of a connection relayed by the SDK
+// base64Data is written to the connection's socket in order with data
+// arriving from the server. Subject to the connection's buffer budget (see
+// SetConnectionBuffer), but unlike server data a full buffer doesn't close
+// the connection. Returns error message or empty string on success
+func (c *Client) WriteData(id string, base64Data string) string {
+	data, err := base64.StdEncoding.DecodeString(base64Data)
+	if err != nil {
+		return fmt.Sprintf("invalid base64 data: %v", err)
+	}
+	if err := c.enqueueData(id, data); err != nil {
+		return fmt.Sprintf("%s: %v", id, err)
+	}
+	return ""
+}
+
+// Errors returned by enqueueData
+var (
+	errUnknownConnection = errors.New("unknown connection")
+	errBufferFull        = errors.New("connection buffer full")
+)
+
+// isRelayed reports whether id is a connection relayed by the SDK
+func (c *Client) isRelayed(id string) bool {
+	c.clientMutex.RLock()
+	defer c.clientMutex.RUnlock()
+	_, ok := c.clientConns[id]
+	return ok
+}
+
+// enqueueData queues data towards a relayed connection's target
+func (c *Client) enqueueData(id string, data []byte) error {
+	c.clientMutex.RLock()
+	cc, ok := c.clientConns[id]
+	c.clientMutex.RUnlock()
+	if !ok {
+		return errUnknownConnection
+	}
+
+	if !c.reserveBuffer(cc, len(data)) {
+		return errBufferFull
+	}
+
+	c.clientMutex.RLock()
+	defer c.clientMutex.RUnlock()
+	if c.clientConns[id] != cc {
+		// Closed while waiting for buffer space
+		return errUnknownConnection
+	}
+	select {
+	case cc.dataChan <- data:
+		cc.enqueued.Add(int64(len(data)))
+		return nil
+	default:
+		return errBufferFull
+	}
+}
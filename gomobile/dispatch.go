@@ -0,0 +1,73 @@
+package vyxclient
+
+import (
+	"hash/fnv"
+)
+
+// dispatchQueueSize is the backlog of callbacks each dispatch worker holds
+// A full queue blocks message handling, applying backpressure to the server.
+const dispatchQueueSize = 256
+
+// callbackDispatcher runs OnMessage callbacks on worker goroutines
+// Each connection ID is pinned to one worker, so callbacks for a connection
+// run in receive order while different connections proceed in parallel.
+type callbackDispatcher struct {
+	shards []chan func()
+}
+
+// SetCallbackWorkers delivers OnMessage callbacks on workers goroutines
+// Messages for the same connection ID are always delivered in order on the
+// same worker; different connections may be delivered concurrently, so the
+// Callback must be safe for concurrent use. 0 (default) delivers inline as
+// messages are handled. Call before Start().
+func (c *Client) SetCallbackWorkers(workers int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.CallbackWorkers = workers
+	})
+}
+
+// startDispatcher creates the dispatch workers if configured
+func (c *Client) startDispatcher() {
+	workers := c.getConfig().CallbackWorkers
+	if workers <= 0 {
+		return
+	}
+
+	d := &callbackDispatcher{shards: make([]chan func(), workers)}
+	for i := range d.shards {
+		queue := make(chan func(), dispatchQueueSize)
+		d.shards[i] = queue
+		go c.runDispatchWorker(queue)
+	}
+	c.dispatcher = d
+}
+
+// runDispatchWorker invokes queued callbacks until the client stops
+func (c *Client) runDispatchWorker(queue chan func()) {
+	for {
+		select {
+		case fn := <-queue:
+			fn()
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// dispatchMessage delivers a message to OnMessage, ordered per connection ID
+func (c *Client) dispatchMessage(msgType string, id string, addr string, data string) {
+	d := c.dispatcher
+	if d == nil {
+		c.callback.OnMessage(msgType, id, addr, data)
+		return
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	queue := d.shards[h.Sum32()%uint32(len(d.shards))]
+
+	select {
+	case queue <- func() { c.callback.OnMessage(msgType, id, addr, data) }:
+	case <-c.ctx.Done():
+	}
+}
@@ -0,0 +1,48 @@
+package vyxclient
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// SetDSCP marks the tunnel's UDP packets with a DiffServ code point (0-63)
+// e.g. 46 (EF) for interactive traffic on QoS-aware networks. Applies to the
+// shared socket immediately if it exists, and to the socket when it's created.
+// Returns error message or empty string on success
+func (c *Client) SetDSCP(dscp int) string {
+	if dscp < 0 || dscp > 63 {
+		return fmt.Sprintf("invalid DSCP value: %d", dscp)
+	}
+
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.DSCP = dscp
+	})
+
+	c.transportMutex.Lock()
+	defer c.transportMutex.Unlock()
+
+	if c.packetConn == nil {
+		return ""
+	}
+	if err := applyDSCP(c.packetConn, dscp); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// applyDSCP sets the ToS / traffic class byte on a UDP socket
+// The socket may be dual-stack, so both families are tried and only a
+// failure of both is reported.
+func applyDSCP(pc net.PacketConn, dscp int) error {
+	tos := dscp << 2
+
+	err4 := ipv4.NewPacketConn(pc).SetTOS(tos)
+	err6 := ipv6.NewPacketConn(pc).SetTrafficClass(tos)
+	if err4 != nil && err6 != nil {
+		return fmt.Errorf("failed to set DSCP: %v", err4)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package vyxclient
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Error delivery limiting
+//
+// Errors reach the app through OnError and global OnMessage("error") calls.
+// The first occurrence of an error is delivered right away; identical errors
+// within the dedupe window are counted instead, and when the window ends a
+// single summary with the repeat count is delivered. On top of that, at most
+// MaxErrorsPerSec new errors are delivered per second; the rest are dropped
+// and reported once a second as an "errors_suppressed" OnError. Errors for a
+// specific connection ID are always delivered, since the app may need them to
+// clean up that connection.
+
+// codeErrorsSuppressed reports errors dropped by the rate cap
+const codeErrorsSuppressed = "errors_suppressed"
+
+// errorLimiter tracks recently delivered errors
+type errorLimiter struct {
+	mu      sync.Mutex
+	recent  map[string]*recentError
+	second  time.Time // start of the current rate window
+	inSec   int
+	dropped int
+	flush   *time.Timer // reports dropped errors
+}
+
+// recentError is an error inside its dedupe window
+type recentError struct {
+	repeats int
+	// deliver reports the error with its repeat count, from the latest occurrence
+	deliver func(repeats int)
+}
+
+// SetErrorLimits configures error deduplication and the delivery rate cap
+// Identical errors within windowMillis are coalesced into one summary;
+// maxPerSecond caps new errors delivered per second. 0 disables either.
+func (c *Client) SetErrorLimits(windowMillis int, maxPerSecond int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.ErrorDedupeWindowMs = windowMillis
+		cfg.MaxErrorsPerSec = maxPerSecond
+	})
+}
+
+// limitError delivers an error identified by key subject to the limits
+// deliver is called now with 0 repeats, later with the repeat count, or not
+// at all if the rate cap drops it.
+func (c *Client) limitError(key string, deliver func(repeats int)) {
+	cfg := c.getConfig()
+	window := time.Duration(cfg.ErrorDedupeWindowMs) * time.Millisecond
+	if window <= 0 && cfg.MaxErrorsPerSec <= 0 {
+		deliver(0)
+		return
+	}
+
+	l := &c.errLimit
+	l.mu.Lock()
+	if entry, ok := l.recent[key]; ok {
+		entry.repeats++
+		entry.deliver = deliver
+		l.mu.Unlock()
+		return
+	}
+
+	if cfg.MaxErrorsPerSec > 0 {
+		now := time.Now()
+		if now.Sub(l.second) >= time.Second {
+			l.second = now
+			l.inSec = 0
+		}
+		if l.inSec >= cfg.MaxErrorsPerSec {
+			l.dropped++
+			if l.flush == nil {
+				l.flush = time.AfterFunc(time.Second, c.reportDroppedErrors)
+			}
+			l.mu.Unlock()
+			return
+		}
+		l.inSec++
+	}
+
+	if window > 0 {
+		if l.recent == nil {
+			l.recent = make(map[string]*recentError)
+		}
+		l.recent[key] = &recentError{deliver: deliver}
+		time.AfterFunc(window, func() {
+			l.mu.Lock()
+			entry := l.recent[key]
+			delete(l.recent, key)
+			l.mu.Unlock()
+
+			if entry != nil && entry.repeats > 0 {
+				entry.deliver(entry.repeats)
+			}
+		})
+	}
+	l.mu.Unlock()
+
+	deliver(0)
+}
+
+// reportDroppedErrors tells the app how many errors the rate cap dropped
+func (c *Client) reportDroppedErrors() {
+	l := &c.errLimit
+	l.mu.Lock()
+	dropped := l.dropped
+	l.dropped = 0
+	l.flush = nil
+	l.mu.Unlock()
+
+	if dropped == 0 {
+		return
+	}
+	c.log(fmt.Sprintf("Suppressed %d errors over the rate limit", dropped))
+	if cb, ok := c.callback.(ErrorCallback); ok {
+		cb.OnError(codeErrorsSuppressed, fmt.Sprintf("%d errors suppressed by rate limit", dropped))
+	}
+}
+
+// withRepeats appends the repeat count of a coalesced error to message
+func withRepeats(message string, repeats int) string {
+	if repeats == 0 {
+		return message
+	}
+	return fmt.Sprintf("%s (repeated %d times)", message, repeats)
+}
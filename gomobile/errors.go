@@ -0,0 +1,55 @@
+package vyxclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+)
+
+// Error reasons surfaced through OnError / OnDisconnected
+const (
+	// reasonTLSCertInvalid means the server's certificate failed verification
+	// (expired, not yet valid, untrusted or wrong host)
+	reasonTLSCertInvalid = "tls_cert_invalid"
+	// reasonTLSPinMismatch means the certificate didn't match SetPinnedCertSHA256
+	reasonTLSPinMismatch = "tls_pin_mismatch"
+	reasonConnectFailed  = "connect_failed"
+	// reasonAuthFailed means the server rejected the token or auth timed out
+	reasonAuthFailed = "auth_failed"
+)
+
+// errAuthFailed is returned by connect when authentication doesn't succeed
+var errAuthFailed = errors.New("authentication failed")
+
+// classifyConnectError maps a connect() error to a reason string
+func classifyConnectError(err error) string {
+	if errors.Is(err, errPinMismatch) {
+		return reasonTLSPinMismatch
+	}
+	if isCertificateError(err) {
+		return reasonTLSCertInvalid
+	}
+	if errors.Is(err, errAuthFailed) {
+		return reasonAuthFailed
+	}
+	return reasonConnectFailed
+}
+
+// isCertificateError reports whether err was caused by certificate validation
+func isCertificateError(err error) bool {
+	var verifyErr *tls.CertificateVerificationError
+	var invalidErr x509.CertificateInvalidError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+
+	return errors.As(err, &verifyErr) ||
+		errors.As(err, &invalidErr) ||
+		errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr)
+}
+
+// isTrustFailure reports whether reason means the server couldn't be trusted
+// Retrying soon won't help, so these are reported to the app and backed off.
+func isTrustFailure(reason string) bool {
+	return reason == reasonTLSCertInvalid || reason == reasonTLSPinMismatch
+}
@@ -0,0 +1,244 @@
+package vyxclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultSlowDownDuration applies when "slow_down" doesn't say how long
+const defaultSlowDownDuration = 30 * time.Second
+
+// ThrottleCallback is an optional extension of Callback
+// OnThrottled fires when the server asks the client to slow down opening
+// connections ("slow_down") and again when normal rate resumes.
+type ThrottleCallback interface {
+	OnThrottled(throttled bool)
+}
+
+// slowDownRequest is the payload of a server "slow_down" message
+type slowDownRequest struct {
+	// Rate is the allowed connection establishments per second
+	Rate float64 `json:"rate"`
+	// DurationMs is how long to stay throttled unless "resume" arrives first
+	DurationMs int `json:"durationMs"`
+}
+
+// establishGate limits how fast and how many connections are established
+// Once all slots are taken, waiters queue and are admitted by priority,
+// oldest first within a priority, so no connection is starved by newer ones.
+type establishGate struct {
+	mu        sync.Mutex
+	active    int
+	queue     []*establishWaiter
+	nextSeq   uint64
+	throttled bool
+	interval  time.Duration
+	nextStart time.Time
+	timer     *time.Timer
+}
+
+// establishWaiter is a queued request for an establish slot
+type establishWaiter struct {
+	priority int
+	seq      uint64
+	granted  chan struct{}
+}
+
+// SetMaxConcurrentDials caps simultaneous dials by the Go-side dialer
+func (c *Client) SetMaxConcurrentDials(max int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.MaxConcurrentDials = max
+	})
+
+	// A larger limit admits queued waiters right away
+	c.establish.mu.Lock()
+	c.grantEstablishLocked()
+	c.establish.mu.Unlock()
+}
+
+// establishSlots returns the configured number of concurrent establishments
+func (c *Client) establishSlots() int {
+	size := c.getConfig().MaxConcurrentDials
+	if size <= 0 {
+		size = 1
+	}
+	return size
+}
+
+// acquireEstablishSlot waits for permission to establish a connection
+// priority orders queued waiters (PriorityInteractive before PriorityBulk).
+// Returns the function that releases the slot.
+func (c *Client) acquireEstablishSlot(ctx context.Context, priority int) (func(), error) {
+	if priority < PriorityInteractive || priority > PriorityBulk {
+		priority = PriorityInteractive
+	}
+
+	c.establish.mu.Lock()
+	// While throttled, starts are paced to the server-requested rate
+	var wait time.Duration
+	if c.establish.throttled {
+		now := time.Now()
+		start := c.establish.nextStart
+		if start.Before(now) {
+			start = now
+		}
+		c.establish.nextStart = start.Add(c.establish.interval)
+		wait = start.Sub(now)
+	}
+	c.establish.mu.Unlock()
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+
+	c.establish.mu.Lock()
+	waiter := &establishWaiter{priority: priority, seq: c.establish.nextSeq, granted: make(chan struct{})}
+	c.establish.nextSeq++
+	c.establish.queue = append(c.establish.queue, waiter)
+	c.grantEstablishLocked()
+	c.establish.mu.Unlock()
+
+	select {
+	case <-waiter.granted:
+		return c.releaseEstablishSlot, nil
+	case <-ctx.Done():
+		c.establish.mu.Lock()
+		if c.removeEstablishWaiterLocked(waiter) {
+			c.establish.mu.Unlock()
+			return nil, ctx.Err()
+		}
+		c.establish.mu.Unlock()
+		// Granted while giving up; hand the slot on
+		c.releaseEstablishSlot()
+		return nil, ctx.Err()
+	}
+}
+
+// releaseEstablishSlot frees a slot and admits the next waiter
+func (c *Client) releaseEstablishSlot() {
+	c.establish.mu.Lock()
+	c.establish.active--
+	c.grantEstablishLocked()
+	c.establish.mu.Unlock()
+}
+
+// grantEstablishLocked admits queued waiters while slots are free
+// Caller must hold establish.mu.
+func (c *Client) grantEstablishLocked() {
+	size := c.establishSlots()
+	for c.establish.active < size && len(c.establish.queue) > 0 {
+		next := 0
+		for i, w := range c.establish.queue {
+			best := c.establish.queue[next]
+			if w.priority < best.priority || (w.priority == best.priority && w.seq < best.seq) {
+				next = i
+			}
+		}
+		waiter := c.establish.queue[next]
+		c.establish.queue = append(c.establish.queue[:next], c.establish.queue[next+1:]...)
+		c.establish.active++
+		close(waiter.granted)
+	}
+}
+
+// removeEstablishWaiterLocked drops an abandoned waiter from the queue
+// Returns false if it was already granted a slot. Caller must hold establish.mu.
+func (c *Client) removeEstablishWaiterLocked(waiter *establishWaiter) bool {
+	for i, w := range c.establish.queue {
+		if w == waiter {
+			c.establish.queue = append(c.establish.queue[:i], c.establish.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// establishQueueSnapshot returns the number of queued establishments, total and per priority
+func (c *Client) establishQueueSnapshot() (int, map[string]int) {
+	c.establish.mu.Lock()
+	defer c.establish.mu.Unlock()
+
+	byPriority := map[string]int{"interactive": 0, "bulk": 0}
+	for _, w := range c.establish.queue {
+		if w.priority == PriorityBulk {
+			byPriority["bulk"]++
+		} else {
+			byPriority["interactive"]++
+		}
+	}
+	return len(c.establish.queue), byPriority
+}
+
+// handleSlowDown throttles connection establishment as requested by the server
+func (c *Client) handleSlowDown(data string) {
+	var req slowDownRequest
+	if data != "" {
+		if err := json.Unmarshal([]byte(data), &req); err != nil {
+			c.log(fmt.Sprintf("Invalid slow_down payload: %v", err))
+		}
+	}
+	if req.Rate <= 0 {
+		req.Rate = 1
+	}
+	duration := time.Duration(req.DurationMs) * time.Millisecond
+	if duration <= 0 {
+		duration = defaultSlowDownDuration
+	}
+
+	c.establish.mu.Lock()
+	wasThrottled := c.establish.throttled
+	c.establish.throttled = true
+	c.establish.interval = time.Duration(float64(time.Second) / req.Rate)
+	if c.establish.timer != nil {
+		c.establish.timer.Stop()
+	}
+	c.establish.timer = time.AfterFunc(duration, func() {
+		c.resumeEstablishment("cooldown elapsed")
+	})
+	c.establish.mu.Unlock()
+
+	c.log(fmt.Sprintf("Server requested slow down: %.2f connections/s for %v", req.Rate, duration))
+	if !wasThrottled {
+		c.notifyThrottled(true)
+	}
+}
+
+// resumeEstablishment lifts server-requested throttling
+func (c *Client) resumeEstablishment(why string) {
+	c.establish.mu.Lock()
+	wasThrottled := c.establish.throttled
+	c.establish.throttled = false
+	if c.establish.timer != nil {
+		c.establish.timer.Stop()
+		c.establish.timer = nil
+	}
+	c.establish.mu.Unlock()
+
+	if wasThrottled {
+		c.log(fmt.Sprintf("Resuming normal connection rate (%s)", why))
+		c.notifyThrottled(false)
+	}
+}
+
+// isThrottled reports whether connection establishment is currently throttled
+func (c *Client) isThrottled() bool {
+	c.establish.mu.Lock()
+	defer c.establish.mu.Unlock()
+	return c.establish.throttled
+}
+
+// notifyThrottled tells the app about throttling changes
+func (c *Client) notifyThrottled(throttled bool) {
+	if cb, ok := c.callback.(ThrottleCallback); ok {
+		cb.OnThrottled(throttled)
+	}
+}
@@ -0,0 +1,257 @@
+package vyxclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// configFormatVersion is bumped when the export format changes incompatibly
+const configFormatVersion = 1
+
+// exportedConfig is the JSON document produced by ExportConfig
+type exportedConfig struct {
+	Version    int          `json:"version"`
+	ServerURL  string       `json:"serverURL"`
+	APIToken   string       `json:"apiToken,omitempty"`
+	ClientType string       `json:"clientType"`
+	Metadata   string       `json:"metadata"`
+	Settings   clientConfig `json:"settings"`
+}
+
+// ExportConfig returns the client's configuration as JSON
+// The API token is only included if includeToken is true; treat such exports as secrets.
+func (c *Client) ExportConfig(includeToken bool) string {
+	exported := c.exportConfig(includeToken)
+	data, err := json.Marshal(exported)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// exportConfig snapshots the configuration
+func (c *Client) exportConfig(includeToken bool) exportedConfig {
+	c.serverMutex.Lock()
+	exported := exportedConfig{
+		Version:    configFormatVersion,
+		ServerURL:  c.serverSpec,
+		ClientType: c.clientType,
+		Metadata:   c.metadata,
+		Settings:   c.getConfig(),
+	}
+	c.serverMutex.Unlock()
+	if includeToken {
+		c.tokenMutex.Lock()
+		exported.APIToken = c.apiToken
+		c.tokenMutex.Unlock()
+	}
+	return exported
+}
+
+// NewClientFromConfig creates a client from JSON produced by ExportConfig
+// Settings missing from the JSON keep their defaults; unknown fields and
+// invalid values are rejected. If the export didn't include the token,
+// supply one with SetToken or SetTokenProvider before Start().
+func NewClientFromConfig(configJSON string, callback Callback) (*Client, error) {
+	exported, err := parseConfig(configJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewClient(exported.ServerURL, exported.APIToken, exported.ClientType, exported.Metadata, callback)
+	c.applySettings(exported.Settings)
+	return c, nil
+}
+
+// UpdateConfig replaces the whole configuration at runtime
+// configJSON uses the ExportConfig format; settings missing from it reset to
+// their defaults and the token is only replaced if present. Everything is
+// validated before anything is applied. If the server, metadata or network
+// type changed while connected, the client reconnects exactly once.
+// Returns error message or empty string on success
+func (c *Client) UpdateConfig(configJSON string) string {
+	exported, err := parseConfig(configJSON)
+	if err != nil {
+		return err.Error()
+	}
+
+	previous := c.getConfig()
+
+	c.serverMutex.Lock()
+	serverChanged := exported.ServerURL != c.serverSpec
+	metadataChanged := exported.Metadata != c.metadata
+	if serverChanged {
+		c.setServersLocked(exported.ServerURL)
+	}
+	c.clientType = exported.ClientType
+	c.metadata = exported.Metadata
+	c.serverMutex.Unlock()
+
+	if exported.APIToken != "" {
+		c.SetToken(exported.APIToken)
+	}
+
+	c.applySettings(exported.Settings)
+	c.log("Configuration updated")
+
+	next := exported.Settings
+	timersChanged := next.NetworkType != previous.NetworkType ||
+		next.QUICIdleTimeoutMs != previous.QUICIdleTimeoutMs ||
+		next.QUICKeepAlivePeriodMs != previous.QUICKeepAlivePeriodMs
+	if next.LocalAddr != previous.LocalAddr {
+		c.replaceTransport("Local address changed")
+	}
+	if serverChanged || metadataChanged || timersChanged {
+		c.requestReconnect("Configuration changed")
+	}
+	return ""
+}
+
+// parseConfig decodes and validates an exported configuration
+func parseConfig(configJSON string) (exportedConfig, error) {
+	exported := exportedConfig{Settings: defaultConfig()}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(configJSON)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&exported); err != nil {
+		return exportedConfig{}, fmt.Errorf("invalid config: %w", err)
+	}
+
+	if exported.Version > configFormatVersion {
+		return exportedConfig{}, fmt.Errorf("unsupported config version %d (max %d)", exported.Version, configFormatVersion)
+	}
+	if exported.ServerURL == "" {
+		return exportedConfig{}, errors.New("invalid config: serverURL is required")
+	}
+	if err := validateServerList(exported.ServerURL); err != nil {
+		return exportedConfig{}, fmt.Errorf("invalid config: %w", err)
+	}
+	if err := validateMetadata(exported.Metadata); err != nil {
+		return exportedConfig{}, fmt.Errorf("invalid config: %w", err)
+	}
+	if err := validateConfig(exported.Settings); err != nil {
+		return exportedConfig{}, fmt.Errorf("invalid config: %w", err)
+	}
+	return exported, nil
+}
+
+// applySettings installs settings along with the state derived from them
+func (c *Client) applySettings(cfg clientConfig) {
+	c.configMutex.Lock()
+	pinChanged := c.config.PinnedCertSHA256 != cfg.PinnedCertSHA256
+	c.config = cfg
+	c.configMutex.Unlock()
+
+	if pinChanged {
+		c.sessions.clear()
+	}
+
+	c.hist.enabled.Store(cfg.HistogramsEnabled)
+
+	c.limiterMutex.Lock()
+	c.globalLimiter = newTokenBucket(cfg.RateLimitBytesPerSec, cfg.RateLimitBurstBytes)
+	c.limiterMutex.Unlock()
+}
+
+// validateConfig checks settings for out-of-range values
+func validateConfig(cfg clientConfig) error {
+	nonNegative := map[string]int64{
+		"tokenProviderTimeoutMs":   int64(cfg.TokenProviderTimeoutMs),
+		"maxPendingRequests":       int64(cfg.MaxPendingRequests),
+		"dialTimeoutMs":            int64(cfg.DialTimeoutMs),
+		"maxConnections":           int64(cfg.MaxConnections),
+		"rateLimitBytesPerSec":     cfg.RateLimitBytesPerSec,
+		"rateLimitBurstBytes":      cfg.RateLimitBurstBytes,
+		"connRateLimitBytesPerSec": cfg.ConnRateLimitBytesPerSec,
+		"connRateLimitBurstBytes":  cfg.ConnRateLimitBurstBytes,
+		"flapCount":                int64(cfg.FlapCount),
+		"flapThresholdMs":          int64(cfg.FlapThresholdMs),
+		"flapWindowMs":             int64(cfg.FlapWindowMs),
+		"flapCooldownMs":           int64(cfg.FlapCooldownMs),
+		"writeCoalesceDelayMs":     int64(cfg.WriteCoalesceDelayMs),
+		"defaultConnectionTTLMs":   int64(cfg.DefaultConnectionTTLMs),
+		"maxConcurrentDials":       int64(cfg.MaxConcurrentDials),
+		"breakerFailures":          int64(cfg.BreakerFailures),
+		"breakerWindowMs":          int64(cfg.BreakerWindowMs),
+		"breakerCooldownMs":        int64(cfg.BreakerCooldownMs),
+		"warmPoolIdleTimeoutMs":    int64(cfg.WarmPoolIdleTimeoutMs),
+		"callbackWorkers":          int64(cfg.CallbackWorkers),
+		"tcpKeepAlivePeriodMs":     int64(cfg.TCPKeepAlivePeriodMs),
+		"initialDataWaitMs":        int64(cfg.InitialDataWaitMs),
+		"maxConnectionLifetimeMs":  int64(cfg.MaxConnectionLifetimeMs),
+		"lifetimeDrainMs":          int64(cfg.LifetimeDrainMs),
+		"streamOpenTimeoutMs":      int64(cfg.StreamOpenTimeoutMs),
+		"redirectDrainMs":          int64(cfg.RedirectDrainMs),
+		"relayWorkers":             int64(cfg.RelayWorkers),
+		"pathCheckIntervalMs":      int64(cfg.PathCheckIntervalMs),
+		"errorDedupeWindowMs":      int64(cfg.ErrorDedupeWindowMs),
+		"maxErrorsPerSec":          int64(cfg.MaxErrorsPerSec),
+		"connBufferBytes":          cfg.ConnBufferBytes,
+		"connIdleTimeoutMs":        int64(cfg.ConnIdleTimeoutMs),
+		"watchdogTimeoutMs":        int64(cfg.WatchdogTimeoutMs),
+		"serverQuarantineMs":       int64(cfg.ServerQuarantineMs),
+		"writeTimeoutMs":           int64(cfg.WriteTimeoutMs),
+		"reconnectStableMs":        int64(cfg.ReconnectStableMs),
+		"connectTimeoutMs":         int64(cfg.ConnectTimeoutMs),
+		"compressMinBytes":         int64(cfg.CompressMinBytes),
+	}
+	for name, v := range nonNegative {
+		if v < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+
+	if _, err := resolveLocalAddr(cfg.LocalAddr); err != nil {
+		return err
+	}
+	if cfg.AuthTimeoutMs <= 0 {
+		return fmt.Errorf("authTimeoutMs must be positive")
+	}
+	if cfg.LogLevel < LogLevelOff || cfg.LogLevel > LogLevelDebug {
+		return fmt.Errorf("logLevel must be between %d and %d", LogLevelOff, LogLevelDebug)
+	}
+	if cfg.DSCP < 0 || cfg.DSCP > 63 {
+		return fmt.Errorf("dscp must be between 0 and 63")
+	}
+	for addr, size := range cfg.WarmPool {
+		if size < 0 || size > maxWarmPerDestination {
+			return fmt.Errorf("warmPool size for %s must be between 0 and %d", addr, maxWarmPerDestination)
+		}
+	}
+	if cfg.ConnBufferPolicy != BufferPolicyClose && cfg.ConnBufferPolicy != BufferPolicyBlock {
+		return fmt.Errorf("unknown connBufferPolicy %q", cfg.ConnBufferPolicy)
+	}
+	if cfg.RelayMode != RelayModeGoroutines && cfg.RelayMode != RelayModePool {
+		return fmt.Errorf("unknown relayMode %q", cfg.RelayMode)
+	}
+	if err := validateRelayBuffer(cfg.RelayBufferBytes); err != nil {
+		return err
+	}
+	if err := validateReconnectPolicy(cfg); err != nil {
+		return err
+	}
+	if _, err := decodePin(cfg.PinnedCertSHA256); err != nil {
+		return err
+	}
+	if _, err := normalizeCipherSuites(cfg.TLSCipherSuites); err != nil {
+		return err
+	}
+	if err := validateResolver(cfg); err != nil {
+		return err
+	}
+	if _, ok := networkProfiles[cfg.NetworkType]; !ok {
+		return fmt.Errorf("unknown networkType %q", cfg.NetworkType)
+	}
+	if err := validateQUICTimeouts(cfg); err != nil {
+		return err
+	}
+	if cfg.DialTimeoutMs == 0 {
+		return errors.New("dialTimeoutMs must be positive")
+	}
+	if cfg.StreamOpenTimeoutMs == 0 {
+		return errors.New("streamOpenTimeoutMs must be positive")
+	}
+	return nil
+}
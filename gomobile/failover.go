@@ -0,0 +1,124 @@
+package vyxclient
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Server failover
+//
+// The client tries its servers in list order: the configured ones, then the
+// built-in fallbacks. After three consecutive connect failures it moves to the
+// next server. A server that rejects authentication for a reason other than
+// the token (server error, timeout, malformed response, rate limiting) is
+// quarantined for ServerQuarantineMs and skipped while others are available.
+// After a connection that was stable for serverFailbackAfter drops, the next
+// attempt starts again from the first server.
+
+// serverFailbackAfter is how long a connection must last before the client
+// returns to the primary server once it drops
+const serverFailbackAfter = time.Minute
+
+// SetServerList sets the servers to try in priority order, comma-separated
+// e.g. "eu1.example.com:8443,us1.example.com:8443". The built-in fallbacks
+// follow. The current connection is kept until it next reconnects.
+// Returns error message or empty string on success
+func (c *Client) SetServerList(servers string) string {
+	return c.UpdateServerURL(servers, false)
+}
+
+// SetServerQuarantine sets how long a server failing authentication is skipped
+// 0 disables quarantining.
+func (c *Client) SetServerQuarantine(quarantineMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.ServerQuarantineMs = quarantineMillis
+	})
+}
+
+// GetActiveServer returns the server of the current connection, "" if disconnected
+func (c *Client) GetActiveServer() string {
+	if !c.IsConnected() {
+		return ""
+	}
+	c.serverMutex.Lock()
+	defer c.serverMutex.Unlock()
+	return c.activeServer
+}
+
+// validateServerList checks every entry of a comma-separated server list
+func validateServerList(servers string) error {
+	count := 0
+	for _, s := range strings.Split(servers, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		if _, err := normalizeServerAddr(s); err != nil {
+			return err
+		}
+		count++
+	}
+	if count == 0 {
+		return fmt.Errorf("no server address given")
+	}
+	return nil
+}
+
+// setServersLocked replaces the server list, c.serverMutex must be held
+func (c *Client) setServersLocked(servers string) {
+	c.serverSpec = servers
+	c.serverList = buildServerList(servers)
+	c.currentServerIdx = 0
+	c.serverURL = c.serverList[0]
+}
+
+// quarantineServer skips server for the configured quarantine period
+func (c *Client) quarantineServer(server string) {
+	period := time.Duration(c.getConfig().ServerQuarantineMs) * time.Millisecond
+	if period <= 0 {
+		return
+	}
+
+	c.serverMutex.Lock()
+	if c.quarantined == nil {
+		c.quarantined = make(map[string]time.Time)
+	}
+	c.quarantined[server] = time.Now().Add(period)
+	c.serverMutex.Unlock()
+
+	c.log(fmt.Sprintf("Quarantining %s for %v after failed authentication", server, period))
+}
+
+// isQuarantinedLocked reports whether server is being skipped, c.serverMutex must be held
+func (c *Client) isQuarantinedLocked(server string) bool {
+	until, ok := c.quarantined[server]
+	if !ok {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(c.quarantined, server)
+		return false
+	}
+	return true
+}
+
+// failbackToPrimary returns to the first usable server after a stable connection
+func (c *Client) failbackToPrimary(connectedFor time.Duration) {
+	if connectedFor < serverFailbackAfter {
+		return
+	}
+
+	c.serverMutex.Lock()
+	defer c.serverMutex.Unlock()
+	for i, server := range c.serverList {
+		if c.isQuarantinedLocked(server) {
+			continue
+		}
+		if server != c.serverURL {
+			c.log(fmt.Sprintf("Returning to %s", server))
+			c.currentServerIdx = i
+			c.serverURL = server
+		}
+		return
+	}
+}
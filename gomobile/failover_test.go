@@ -0,0 +1,113 @@
+package vyxclient
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// TestRotateServerOrder fails over twice from a quarantined primary and
+// checks each step moves on from the server actually in use
+func TestRotateServerOrder(t *testing.T) {
+	c := NewClient("a.example.com:8443,b.example.com:8443,c.example.com:8443", "test-token", "test", "{}", nil)
+	defer c.Stop()
+	c.SetSystemLog(false)
+
+	c.quarantineServer("a.example.com:8443")
+	var tried []string
+	for i := 0; i < 3; i++ {
+		c.rotateServer()
+		tried = append(tried, c.currentServer())
+	}
+
+	want := []string{"b.example.com:8443", "c.example.com:8443", "us.vyx.network:8443"}
+	if !reflect.DeepEqual(tried, want) {
+		t.Fatalf("servers tried = %v, want %v", tried, want)
+	}
+}
+
+// TestFailbackToPrimary returns to the first server after a stable connection
+func TestFailbackToPrimary(t *testing.T) {
+	c := NewClient("a.example.com:8443,b.example.com:8443", "test-token", "test", "{}", nil)
+	defer c.Stop()
+	c.SetSystemLog(false)
+
+	c.rotateServer()
+	c.failbackToPrimary(serverFailbackAfter / 2)
+	if got := c.currentServer(); got != "b.example.com:8443" {
+		t.Fatalf("short connection moved to %s", got)
+	}
+
+	c.failbackToPrimary(serverFailbackAfter)
+	if got := c.currentServer(); got != "a.example.com:8443" {
+		t.Fatalf("after failback server = %s, want a.example.com:8443", got)
+	}
+	c.rotateServer()
+	if got := c.currentServer(); got != "b.example.com:8443" {
+		t.Fatalf("rotation after failback = %s, want b.example.com:8443", got)
+	}
+}
+
+// oneShotServer accepts one authentication, drops it shortly after and then
+// stops listening, so the client has to fail over away from it
+func oneShotServer(t *testing.T) string {
+	t.Helper()
+	cert, _ := selfSignedCert(t)
+	ln, err := quic.ListenAddr("127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}, NextProtos: []string{"vyx-proxy"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept(context.Background())
+		if err != nil {
+			return
+		}
+		stream, err := conn.AcceptStream(context.Background())
+		if err != nil {
+			return
+		}
+		if _, err := bufio.NewReader(stream).ReadBytes('\n'); err != nil {
+			return
+		}
+		stream.Write([]byte(`{"type":"auth_success","id":""}` + "\n"))
+		time.Sleep(50 * time.Millisecond)
+		ln.Close()
+		conn.CloseWithError(0, "going away")
+	}()
+	return ln.Addr().String()
+}
+
+// TestFailoverOrderAfterConnect fails over twice, with a successful connection
+// in between, and checks each rotation moves on from the server in use
+func TestFailoverOrderAfterConnect(t *testing.T) {
+	c, cb := newTestClient(t)
+	defer c.Stop()
+	live := oneShotServer(t)
+	first, third := "127.0.0.1:1", "127.0.0.2:1"
+	if msg := c.UpdateServerURL(first+","+live+","+third, false); msg != "" {
+		t.Fatal(msg)
+	}
+	c.Start()
+
+	want := []string{
+		"Rotating server: " + first + " -> " + live,
+		"Rotating server: " + live + " -> " + third,
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for len(cb.logged("Rotating server")) < len(want) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := cb.logged("Rotating server"); !reflect.DeepEqual(got[:min(len(got), len(want))], want) {
+		t.Fatalf("rotations = %v, want %v first", got, want)
+	}
+	if n := c.stats.connects.Load(); n != 1 {
+		t.Fatalf("connects = %d, want 1", n)
+	}
+}
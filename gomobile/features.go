@@ -0,0 +1,72 @@
+package vyxclient
+
+import (
+	"encoding/json"
+)
+
+// featureState describes one optional feature in GetActiveFeatures
+// Enabled is the local setting, Negotiated whether the server agreed (only
+// for negotiated features) and Active whether it's in effect right now.
+type featureState struct {
+	Enabled    bool  `json:"enabled"`
+	Negotiated *bool `json:"negotiated,omitempty"`
+	Active     bool  `json:"active"`
+}
+
+// GetActiveFeatures returns JSON mapping each optional feature to its state
+// e.g. {"connectResultWithData": {"enabled": true, "negotiated": false, "active": false}, ...}
+func (c *Client) GetActiveFeatures() string {
+	data, err := json.Marshal(c.activeFeatures())
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// activeFeatures composes the config with the current session's negotiation
+func (c *Client) activeFeatures() map[string]featureState {
+	cfg := c.getConfig()
+	connected := c.IsConnected()
+
+	c.quicMutex.Lock()
+	conn := c.quicConn
+	c.quicMutex.Unlock()
+
+	used0RTT := false
+	if conn != nil && connected {
+		used0RTT = conn.ConnectionState().Used0RTT
+	}
+
+	local := func(enabled bool) featureState {
+		return featureState{Enabled: enabled, Active: enabled && connected}
+	}
+	negotiated := func(enabled bool, capName string) featureState {
+		agreed := c.hasCap(capName)
+		return featureState{Enabled: enabled, Negotiated: &agreed, Active: enabled && agreed && connected}
+	}
+
+	return map[string]featureState{
+		"connectResultWithData": negotiated(cfg.ConnectResultWithData, capConnectResultWithData),
+		"zeroRTT":               {Enabled: cfg.ZeroRTT, Active: used0RTT},
+		"localDialing":          local(cfg.LocalDialing),
+		"writeCoalescing":       local(cfg.WriteCoalesceDelayMs > 0),
+		"messageTimestamps":     local(cfg.MessageTimestamps),
+		"rateLimit":             local(cfg.RateLimitBytesPerSec > 0 || cfg.ConnRateLimitBytesPerSec > 0),
+		"circuitBreaker":        local(cfg.BreakerFailures > 0),
+		"flapProtection":        local(cfg.FlapCount > 0),
+		"connectionLifetime":    local(cfg.MaxConnectionLifetimeMs > 0),
+		"warmPool":              local(len(cfg.WarmPool) > 0),
+		"callbackWorkers":       local(cfg.CallbackWorkers > 0),
+		"relayPool":             local(cfg.RelayMode == RelayModePool),
+		"secureResolver":        local(cfg.ResolverMode != ResolverSystem),
+		"healthCheck":           local(cfg.HealthCheckIntervalMs > 0 && cfg.HealthCheckTarget != ""),
+		"dscp":                  local(cfg.DSCP > 0),
+		"histograms":            local(cfg.HistogramsEnabled),
+		"connectionStreams":     negotiated(cfg.ConnectionStreams, capConnStreams),
+		"binaryData":            negotiated(cfg.BinaryData, capBinaryData),
+		"tlsCipherSuites":       local(cfg.TLSCipherSuites != ""),
+		"metadataUpdate":        negotiated(true, capMetadataUpdate),
+		"compression":           negotiated(cfg.Compression, capCompression),
+		"connectionMigration":   local(cfg.ConnectionMigration),
+	}
+}
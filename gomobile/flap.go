@@ -0,0 +1,66 @@
+package vyxclient
+
+import (
+	"fmt"
+	"time"
+)
+
+// FlapCallback is an optional extension of Callback
+// OnFlapDetected fires when the connection keeps dropping right after
+// connecting and the client enters its flap cooldown.
+type FlapCallback interface {
+	OnFlapDetected()
+}
+
+// SetFlapProtection configures detection of repeated immediate disconnects
+// If count connections each last less than thresholdMillis within windowMillis,
+// the client waits cooldownMillis before reconnecting. count 0 disables.
+func (c *Client) SetFlapProtection(count int, thresholdMillis int, windowMillis int, cooldownMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.FlapCount = count
+		cfg.FlapThresholdMs = thresholdMillis
+		cfg.FlapWindowMs = windowMillis
+		cfg.FlapCooldownMs = cooldownMillis
+	})
+}
+
+// recordDisconnect tracks how long a connection lasted
+// Returns the cooldown to apply if the connection is flapping, or 0.
+func (c *Client) recordDisconnect(connectedFor time.Duration) time.Duration {
+	cfg := c.getConfig()
+	if cfg.FlapCount <= 0 {
+		return 0
+	}
+	if connectedFor >= time.Duration(cfg.FlapThresholdMs)*time.Millisecond {
+		return 0
+	}
+
+	now := time.Now()
+	window := time.Duration(cfg.FlapWindowMs) * time.Millisecond
+
+	c.retryMutex.Lock()
+	recent := c.flapTimes[:0]
+	for _, t := range c.flapTimes {
+		if now.Sub(t) <= window {
+			recent = append(recent, t)
+		}
+	}
+	c.flapTimes = append(recent, now)
+	flapping := len(c.flapTimes) >= cfg.FlapCount
+	if flapping {
+		c.flapTimes = nil
+	}
+	c.retryMutex.Unlock()
+
+	if !flapping {
+		return 0
+	}
+
+	cooldown := time.Duration(cfg.FlapCooldownMs) * time.Millisecond
+	c.log(fmt.Sprintf("Connection flapping (%d drops within %v), cooling down for %v",
+		cfg.FlapCount, window, cooldown))
+	if cb, ok := c.callback.(FlapCallback); ok {
+		cb.OnFlapDetected()
+	}
+	return cooldown
+}
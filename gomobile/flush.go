@@ -0,0 +1,61 @@
+package vyxclient
+
+import (
+	"time"
+)
+
+// FlushAll drains the SDK's internal buffers to the wire
+// Waits until everything queued at the time of the call has been written:
+// the coalesced control-stream writes and each relayed connection's queue
+// towards its target. Data arriving during the flush isn't waited for, so a
+// busy connection can't keep it from completing, and a stuck connection
+// only makes it time out. Use it before a deliberate reconnect, a network
+// change or an app checkpoint.
+// Returns the number of bytes flushed, or -1 if timeoutMs passed first.
+func (c *Client) FlushAll(timeoutMs int) int64 {
+	deadline := time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)
+
+	// Snapshot what each connection has queued right now
+	type target struct {
+		cc      *Connection
+		goal    int64
+		written int64
+	}
+	c.clientMutex.RLock()
+	targets := make([]target, 0, len(c.clientConns))
+	for _, cc := range c.clientConns {
+		targets = append(targets, target{cc: cc, goal: cc.enqueued.Load(), written: cc.written.Load()})
+	}
+	c.clientMutex.RUnlock()
+
+	flushed := c.flushControlStream()
+
+	for _, t := range targets {
+		for t.cc.written.Load() < t.goal && t.cc.ctx.Err() == nil {
+			if time.Now().After(deadline) {
+				return -1
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		if t.cc.ctx.Err() == nil {
+			flushed += t.goal - t.written
+		}
+	}
+
+	// Pick up anything the relays wrote to the control stream meanwhile
+	flushed += c.flushControlStream()
+	return flushed
+}
+
+// flushControlStream writes out coalesced control-stream data
+// Returns the number of bytes written.
+func (c *Client) flushControlStream() int64 {
+	c.quicMutex.Lock()
+	writer := c.streamWriter
+	c.quicMutex.Unlock()
+
+	if writer == nil {
+		return 0
+	}
+	return int64(writer.flushCount())
+}
@@ -2,14 +2,16 @@ module github.com/vyx/mobile
 
 go 1.25
 
-require github.com/quic-go/quic-go v0.55.0
+require (
+	github.com/quic-go/quic-go v0.55.0
+	golang.org/x/net v0.46.0
+)
 
 require (
 	go.uber.org/mock v0.6.0 // indirect
 	golang.org/x/crypto v0.43.0 // indirect
 	golang.org/x/mobile v0.0.0-20251009145931-8baca8bf4eeb // indirect
 	golang.org/x/mod v0.29.0 // indirect
-	golang.org/x/net v0.46.0 // indirect
 	golang.org/x/sync v0.17.0 // indirect
 	golang.org/x/sys v0.37.0 // indirect
 	golang.org/x/tools v0.38.0 // indirect
@@ -0,0 +1,111 @@
+package vyxclient
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Half-close propagation for per-connection streams
+//
+// When a Go-side connection has its own QUIC stream, FIN is carried across
+// the stream/TCP boundary in both directions:
+//   - target read EOF (target half-closed) -> stream.Close(), which sends FIN
+//     on the stream's write side while its read side stays open
+//   - stream read EOF (server sent FIN)    -> CloseWrite() on the target
+//     socket once queued data is written, while its read side stays open
+//
+// The connection ends once both directions are done, without a "close"
+// since both FINs already told the server. A failure in either direction
+// still closes it outright. Connections on the control stream, and those
+// serviced by the relay pool, close on target EOF as before; a target that
+// can't half-close is closed when the server sends FIN.
+
+// closeWriter is implemented by connections that support half-close (*net.TCPConn)
+type closeWriter interface {
+	CloseWrite() error
+}
+
+// halfClose tracks which directions of a connection have finished
+type halfClose struct {
+	// peerFIN is closed once the server ends its side of the connection's stream
+	peerFIN chan struct{}
+	once    sync.Once
+	// done counts finished directions, the connection ends at 2
+	done atomic.Int32
+}
+
+// boundConnStream returns the open stream carrying id, or nil
+func (c *Client) boundConnStream(id string) *connStream {
+	s := &c.connStreams
+	s.mu.Lock()
+	cs := s.byID[id]
+	s.mu.Unlock()
+	if cs == nil {
+		return nil
+	}
+	select {
+	case <-cs.ready:
+	default:
+		return nil
+	}
+	if cs.stream == nil {
+		return nil
+	}
+	return cs
+}
+
+// halfCloseFromTarget sends FIN on the connection's stream after a clean target EOF
+// Returns false if the connection has no stream of its own, so the caller
+// closes it instead.
+func (c *Client) halfCloseFromTarget(cc *Connection, id string) bool {
+	cs := c.boundConnStream(id)
+	if cs == nil {
+		return false
+	}
+
+	cs.mu.Lock()
+	err := cs.stream.Close()
+	cs.mu.Unlock()
+	if err != nil {
+		return false
+	}
+	c.logDebug(fmt.Sprintf("Target of %s half-closed, sent FIN", id))
+	c.finishDirection(cc, id)
+	return true
+}
+
+// peerFinished records the server's FIN on a connection's stream
+func (c *Client) peerFinished(id string) {
+	c.clientMutex.RLock()
+	cc, ok := c.clientConns[id]
+	c.clientMutex.RUnlock()
+	if !ok {
+		return
+	}
+	cc.fin.once.Do(func() {
+		close(cc.fin.peerFIN)
+	})
+}
+
+// halfCloseTarget propagates the server's FIN to the target
+// Targets that can't half-close are closed.
+func (c *Client) halfCloseTarget(cc *Connection, id string) {
+	cw, ok := cc.conn.(closeWriter)
+	if !ok || cw.CloseWrite() != nil {
+		c.closeConnection(id, true)
+		return
+	}
+	c.logDebug(fmt.Sprintf("Server half-closed %s, sent FIN to target", id))
+	c.finishDirection(cc, id)
+}
+
+// finishDirection ends the connection once both directions have finished
+func (c *Client) finishDirection(cc *Connection, id string) {
+	if cc.fin.done.Add(1) < 2 {
+		return
+	}
+	if c.closeConnection(id, false) {
+		c.markClosed(id)
+	}
+}
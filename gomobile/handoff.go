@@ -0,0 +1,311 @@
+package vyxclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// handoffFormatVersion is written to ExportConnections output
+const handoffFormatVersion = 1
+
+// Handoff timing
+const (
+	handoffFlushTimeoutMs = 2000
+	handoffDetachTimeout  = 2 * time.Second
+	handoffResumeTimeout  = 5 * time.Second
+)
+
+// reasonResumeFailed closes imported connections the server couldn't resume
+const reasonResumeFailed = "resume_failed"
+
+// Reasons adoptSocket can't import a connection
+var (
+	errSocketUnavailable = errors.New("socket not available in this process")
+	errImportOverflow    = errors.New("pending data exceeds the connection buffer")
+)
+
+// detachedConn is a Go-side socket waiting to be imported by another client
+type detachedConn struct {
+	conn net.Conn
+	// pending holds data from the server not yet written to conn
+	pending [][]byte
+}
+
+// handedOff holds sockets between ExportConnections and ImportConnections
+// Sockets can only move between clients in the same process.
+var handedOff = struct {
+	mu    sync.Mutex
+	conns map[string]*detachedConn
+}{}
+
+// handoffState is the JSON shape of ExportConnections output
+type handoffState struct {
+	Version     int               `json:"version"`
+	Connections []handoffConnJSON `json:"connections"`
+}
+
+// handoffConnJSON describes one handed-off connection
+type handoffConnJSON struct {
+	ID       string `json:"id"`
+	Addr     string `json:"addr,omitempty"`
+	Priority int    `json:"priority"`
+	// Bytes relayed so far towards and from the server, so the server can
+	// check it's resuming at the right offsets
+	SentBytes     int64 `json:"sentBytes"`
+	ReceivedBytes int64 `json:"receivedBytes"`
+	// Socket is the in-process handle of a Go-side socket, empty for
+	// connections the app relays itself
+	Socket string `json:"socket,omitempty"`
+}
+
+// pendingResumes holds imported connections to resume on the server
+type pendingResumes struct {
+	mu    sync.Mutex
+	conns []handoffConnJSON
+}
+
+// ExportConnections detaches active connections for ImportConnections (experimental)
+// Best effort: pending writes are flushed first, then each connection is
+// removed from this client without sending "close". Go-side sockets
+// (local dialing) stay open and survive the handoff only to another Client
+// in the same process; connections the app relays itself are exported by
+// ID and address and the app keeps its own sockets. Payload keys, TTLs and
+// liveness probes don't survive and must be set again. Bytes in flight on
+// this client's QUIC stream may be lost; the byte offsets let the server
+// detect that when resuming.
+func (c *Client) ExportConnections() (string, error) {
+	if c.FlushAll(handoffFlushTimeoutMs) < 0 {
+		c.log("Export: flush timed out, in-flight data may be lost")
+	}
+
+	open := c.openConnections()
+	ids := make([]string, 0, len(open))
+	for id := range open {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	state := handoffState{Version: handoffFormatVersion, Connections: []handoffConnJSON{}}
+	for _, id := range ids {
+		entry := handoffConnJSON{ID: id, Addr: open[id], Priority: c.connectionPriority(id)}
+
+		if cc, detached := c.detachConnection(id); cc != nil {
+			if detached == nil {
+				// The socket closed under us; there's nothing to hand off
+				continue
+			}
+			token, err := randomHex(16)
+			if err != nil {
+				detached.conn.Close()
+				return "", fmt.Errorf("failed to generate socket handle: %w", err)
+			}
+			entry.Socket = token
+			entry.SentBytes = cc.sent.Load()
+			entry.ReceivedBytes = cc.enqueued.Load()
+
+			handedOff.mu.Lock()
+			if handedOff.conns == nil {
+				handedOff.conns = make(map[string]*detachedConn)
+			}
+			handedOff.conns[token] = detached
+			handedOff.mu.Unlock()
+		}
+
+		c.forgetOpen(id)
+		c.connectionEnded(id)
+		state.Connections = append(state.Connections, entry)
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode connections: %w", err)
+	}
+	c.log(fmt.Sprintf("Exported %d connections", len(state.Connections)))
+	return string(data), nil
+}
+
+// ImportConnections adopts connections from another client's ExportConnections (experimental)
+// Each connection is resumed on the server with a "conn_resume" message once
+// this client is connected; connections the server can't resume are closed
+// with reason "resume_failed". Returns error message or empty string on
+// success; on partial failure the message lists the connections not imported.
+func (c *Client) ImportConnections(state string) string {
+	var parsed handoffState
+	if err := json.Unmarshal([]byte(state), &parsed); err != nil {
+		return fmt.Sprintf("invalid connection state: %v", err)
+	}
+	if parsed.Version > handoffFormatVersion {
+		return fmt.Sprintf("unsupported connection state version %d (max %d)", parsed.Version, handoffFormatVersion)
+	}
+
+	var failed, invalid, overflowed []string
+	var imported []handoffConnJSON
+	for _, entry := range parsed.Connections {
+		if entry.ID == "" {
+			continue
+		}
+		// The state may come from anywhere; 0 means interactive as in OpenConnection
+		if entry.Priority == 0 {
+			entry.Priority = PriorityInteractive
+		}
+		if entry.Priority != PriorityInteractive && entry.Priority != PriorityBulk {
+			invalid = append(invalid, entry.ID)
+			continue
+		}
+		if entry.Socket != "" {
+			if err := c.adoptSocket(entry); errors.Is(err, errImportOverflow) {
+				overflowed = append(overflowed, entry.ID)
+				continue
+			} else if err != nil {
+				failed = append(failed, entry.ID)
+				continue
+			}
+		}
+		c.setConnectionPriority(entry.ID, entry.Priority)
+		c.unmarkClosed(entry.ID)
+		c.trackOpen(entry.ID, entry.Addr)
+		c.stats.connectionsOpened.Add(1)
+		c.observe(EventConnectionOpened, map[string]interface{}{"id": entry.ID, "addr": entry.Addr, "origin": "import"})
+		imported = append(imported, entry)
+	}
+
+	c.resumes.mu.Lock()
+	c.resumes.conns = append(c.resumes.conns, imported...)
+	c.resumes.mu.Unlock()
+	if c.IsConnected() {
+		go c.resumeImported()
+	}
+
+	c.log(fmt.Sprintf("Imported %d connections", len(imported)))
+	var problems []string
+	if len(invalid) > 0 {
+		problems = append(problems, fmt.Sprintf("connections with invalid priority: %s", strings.Join(invalid, ",")))
+	}
+	if len(failed) > 0 {
+		problems = append(problems, fmt.Sprintf("connections not available in this process: %s", strings.Join(failed, ",")))
+	}
+	if len(overflowed) > 0 {
+		problems = append(problems, fmt.Sprintf("connections closed with %s: %s", reasonBufferFull, strings.Join(overflowed, ",")))
+	}
+	return strings.Join(problems, "; ")
+}
+
+// detachConnection stops relaying a Go-side connection without closing its socket
+// Returns nil if id isn't relayed by the SDK, and a nil detachedConn if the
+// socket couldn't be kept open.
+func (c *Client) detachConnection(id string) (*Connection, *detachedConn) {
+	c.clientMutex.Lock()
+	cc, ok := c.clientConns[id]
+	if ok {
+		delete(c.clientConns, id)
+	}
+	c.clientMutex.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	if !cc.stopAutoClose() {
+		// Already canceled, so the socket is closing
+		return cc, nil
+	}
+	// Stop both directions, then unblock the reader
+	cc.cancel()
+	cc.conn.SetReadDeadline(time.Now())
+
+	done := make(chan struct{})
+	go func() {
+		cc.relays.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(handoffDetachTimeout):
+		c.log(fmt.Sprintf("Export: relay for %s didn't stop, closing it", id))
+		cc.conn.Close()
+		return cc, nil
+	}
+	cc.conn.SetReadDeadline(time.Time{})
+
+	detached := &detachedConn{conn: cc.conn}
+	for {
+		select {
+		case data := <-cc.dataChan:
+			detached.pending = append(detached.pending, data)
+			continue
+		default:
+		}
+		break
+	}
+	return cc, detached
+}
+
+// adoptSocket registers a socket detached by ExportConnections
+// Pending data that doesn't fit closes the connection with reason
+// "buffer_full" rather than leaving a gap in the stream.
+func (c *Client) adoptSocket(entry handoffConnJSON) error {
+	handedOff.mu.Lock()
+	detached, ok := handedOff.conns[entry.Socket]
+	delete(handedOff.conns, entry.Socket)
+	handedOff.mu.Unlock()
+	if !ok {
+		return errSocketUnavailable
+	}
+
+	pendingBytes := 0
+	for _, data := range detached.pending {
+		pendingBytes += len(data)
+	}
+
+	c.registerConnection(entry.ID, detached.conn)
+
+	c.clientMutex.RLock()
+	cc := c.clientConns[entry.ID]
+	c.clientMutex.RUnlock()
+	if cc == nil {
+		return errSocketUnavailable
+	}
+	cc.sent.Store(entry.SentBytes)
+	cc.written.Store(entry.ReceivedBytes - int64(pendingBytes))
+	cc.enqueued.Store(entry.ReceivedBytes - int64(pendingBytes))
+	for _, data := range detached.pending {
+		select {
+		case cc.dataChan <- data:
+			cc.enqueued.Add(int64(len(data)))
+		default:
+			c.logError(fmt.Sprintf("Import: buffer full for %s, closing it", entry.ID))
+			c.closeWithReason(entry.ID, reasonBufferFull)
+			return errImportOverflow
+		}
+	}
+	return nil
+}
+
+// resumeImported asks the server to reattach imported connections to this session
+func (c *Client) resumeImported() {
+	c.resumes.mu.Lock()
+	conns := c.resumes.conns
+	c.resumes.conns = nil
+	c.resumes.mu.Unlock()
+
+	for _, entry := range conns {
+		offsets, _ := json.Marshal(map[string]int64{"sent": entry.SentBytes, "received": entry.ReceivedBytes})
+		msg := &Message{Type: "conn_resume", ID: entry.ID, Addr: entry.Addr, Data: string(offsets), Priority: entry.Priority}
+
+		response, err := c.request(msg, handoffResumeTimeout)
+		if err == nil && (response.Type == "error" || response.Type == "close") {
+			err = fmt.Errorf("server answered %s: %s", response.Type, response.Data)
+		}
+		if err != nil {
+			c.logError(fmt.Sprintf("Failed to resume connection %s: %v", entry.ID, err))
+			c.closeWithReason(entry.ID, reasonResumeFailed)
+			continue
+		}
+		c.log(fmt.Sprintf("Resumed connection %s", entry.ID))
+	}
+}
@@ -0,0 +1,68 @@
+package vyxclient
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// closedCallback is a testCallback that records OnConnectionClosed
+type closedCallback struct {
+	testCallback
+	closedMu sync.Mutex
+	closed   map[string]string
+}
+
+func (cb *closedCallback) OnConnectionClosed(id string, reason string) {
+	cb.closedMu.Lock()
+	defer cb.closedMu.Unlock()
+	if cb.closed == nil {
+		cb.closed = make(map[string]string)
+	}
+	cb.closed[id] = reason
+}
+
+// TestImportOverflowClosesConnection hands off more pending data than the
+// connection buffer holds and expects the import to close it, not drop data
+func TestImportOverflowClosesConnection(t *testing.T) {
+	cb := &closedCallback{}
+	c := NewClient("127.0.0.1:1", "test-token", "test", "{}", cb)
+	c.SetSystemLog(false)
+	defer c.Stop()
+
+	// Nothing reads the peer, so the writer blocks on the first chunk
+	local, peer := net.Pipe()
+	defer peer.Close()
+	pending := make([][]byte, 10002)
+	for i := range pending {
+		pending[i] = []byte("x")
+	}
+	handedOff.mu.Lock()
+	if handedOff.conns == nil {
+		handedOff.conns = make(map[string]*detachedConn)
+	}
+	handedOff.conns["overflow-socket"] = &detachedConn{conn: local, pending: pending}
+	handedOff.mu.Unlock()
+
+	msg := c.ImportConnections(`{"version":1,"connections":[{"id":"conn-1","addr":"example.com:80","socket":"overflow-socket"}]}`)
+	if !strings.Contains(msg, "closed with buffer_full: conn-1") {
+		t.Fatalf("ImportConnections = %q, want conn-1 reported as buffer_full", msg)
+	}
+
+	cb.closedMu.Lock()
+	reason, ok := cb.closed["conn-1"]
+	cb.closedMu.Unlock()
+	if !ok || reason != reasonBufferFull {
+		t.Fatalf("OnConnectionClosed(conn-1) reason = %q (called %v), want %q", reason, ok, reasonBufferFull)
+	}
+	if c.isOpen("conn-1") {
+		t.Fatal("overflowed connection still tracked as open")
+	}
+	c.clientMutex.RLock()
+	_, registered := c.clientConns["conn-1"]
+	c.clientMutex.RUnlock()
+	if registered {
+		t.Fatal("overflowed connection still registered")
+	}
+}
@@ -0,0 +1,151 @@
+package vyxclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HealthCheckCallback is an optional extension of Callback
+// OnHealthCheck reports the result of each end-to-end tunnel probe;
+// rtt is the time until the expected response arrived, in milliseconds.
+type HealthCheckCallback interface {
+	OnHealthCheck(ok bool, rtt int)
+}
+
+// internalConns routes server messages for SDK-owned connections (health probes)
+type internalConns struct {
+	mu    sync.Mutex
+	sinks map[string]chan *Message
+}
+
+// SetHealthCheck enables periodic end-to-end probes through the tunnel
+// Every interval the SDK opens a connection to target via the server, sends
+// probe (may be empty) and waits for a response containing expect (empty
+// accepts any data). intervalMillis <= 0 disables health checks.
+func (c *Client) SetHealthCheck(target string, probe string, expect string, intervalMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.HealthCheckTarget = target
+		cfg.HealthCheckProbe = probe
+		cfg.HealthCheckExpect = expect
+		cfg.HealthCheckIntervalMs = intervalMillis
+	})
+}
+
+// healthCheckLoop runs probes while the client is running
+func (c *Client) healthCheckLoop() {
+	for c.shouldRun.Load() {
+		cfg := c.getConfig()
+		interval := time.Duration(cfg.HealthCheckIntervalMs) * time.Millisecond
+		if interval <= 0 || cfg.HealthCheckTarget == "" {
+			interval = 5 * time.Second // re-check config periodically
+		} else if c.IsConnected() {
+			ok, rtt := c.runHealthCheck(cfg, interval)
+			if cb, isHC := c.callback.(HealthCheckCallback); isHC {
+				cb.OnHealthCheck(ok, rtt)
+			}
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// runHealthCheck performs a single probe and returns whether it succeeded and its RTT
+func (c *Client) runHealthCheck(cfg clientConfig, timeout time.Duration) (bool, int) {
+	if timeout > 10*time.Second {
+		timeout = 10 * time.Second
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return false, -1
+	}
+	id = "hc-" + id
+
+	sink := c.addInternalConn(id)
+	defer c.removeInternalConn(id)
+
+	start := time.Now()
+	if err := c.sendMessage(&Message{Type: "connect", ID: id, Addr: cfg.HealthCheckTarget}); err != nil {
+		return false, -1
+	}
+	defer c.sendMessage(&Message{Type: "close", ID: id})
+
+	if cfg.HealthCheckProbe != "" {
+		encoded := base64.StdEncoding.EncodeToString([]byte(cfg.HealthCheckProbe))
+		if err := c.sendMessage(&Message{Type: "data", ID: id, Data: encoded}); err != nil {
+			return false, -1
+		}
+	}
+
+	deadline := time.After(timeout)
+	var received []byte
+	for {
+		select {
+		case msg := <-sink:
+			switch msg.Type {
+			case "data":
+				data, err := msg.dataPayload()
+				if err != nil {
+					return false, -1
+				}
+				received = append(received, data...)
+				if bytes.Contains(received, []byte(cfg.HealthCheckExpect)) {
+					return true, int(time.Since(start).Milliseconds())
+				}
+			case "close", "error":
+				c.log(fmt.Sprintf("Health check to %s failed: %s", cfg.HealthCheckTarget, msg.Type))
+				return false, -1
+			}
+		case <-deadline:
+			c.log(fmt.Sprintf("Health check to %s timed out", cfg.HealthCheckTarget))
+			return false, -1
+		case <-c.ctx.Done():
+			return false, -1
+		}
+	}
+}
+
+// addInternalConn registers a sink for an SDK-owned connection
+func (c *Client) addInternalConn(id string) chan *Message {
+	c.internal.mu.Lock()
+	defer c.internal.mu.Unlock()
+
+	if c.internal.sinks == nil {
+		c.internal.sinks = make(map[string]chan *Message)
+	}
+	ch := make(chan *Message, 64)
+	c.internal.sinks[id] = ch
+	return ch
+}
+
+// removeInternalConn unregisters a sink
+func (c *Client) removeInternalConn(id string) {
+	c.internal.mu.Lock()
+	delete(c.internal.sinks, id)
+	c.internal.mu.Unlock()
+}
+
+// deliverInternal routes msg to an SDK-owned connection
+// Returns false if msg belongs to an app or relayed connection.
+func (c *Client) deliverInternal(msg *Message) bool {
+	c.internal.mu.Lock()
+	ch, ok := c.internal.sinks[msg.ID]
+	c.internal.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+	select {
+	case ch <- msg:
+	default:
+		// Probe isn't keeping up; its result no longer matters
+	}
+	return true
+}
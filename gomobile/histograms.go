@@ -0,0 +1,164 @@
+package vyxclient
+
+import (
+	"encoding/json"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Fixed bucket upper bounds; values above the last bound land in an overflow bucket
+var (
+	latencyBoundsMs = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+	chunkBoundsB    = []float64{64, 256, 1024, 4096, 8192, 16384, 32768, 65536}
+)
+
+// histogram counts samples in fixed buckets, lock-free so it can sit on the relay path
+type histogram struct {
+	bounds []float64
+	counts []atomic.Int64 // len(bounds)+1, the last is overflow
+	count  atomic.Int64
+	// sum and max are stored as float64 bits
+	sum atomic.Uint64
+	max atomic.Uint64
+}
+
+// newHistogram returns an empty histogram over bounds
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]atomic.Int64, len(bounds)+1)}
+}
+
+// record adds one sample
+func (h *histogram) record(v float64) {
+	i := 0
+	for i < len(h.bounds) && v > h.bounds[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.count.Add(1)
+
+	for {
+		old := h.sum.Load()
+		if h.sum.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			break
+		}
+	}
+	for {
+		old := h.max.Load()
+		if v <= math.Float64frombits(old) || h.max.CompareAndSwap(old, math.Float64bits(v)) {
+			break
+		}
+	}
+}
+
+// histogramJSON is the JSON shape of one histogram in GetHistograms
+type histogramJSON struct {
+	Bounds []float64 `json:"bounds"`
+	Counts []int64   `json:"counts"`
+	Count  int64     `json:"count"`
+	Sum    float64   `json:"sum"`
+	Max    float64   `json:"max"`
+}
+
+// snapshot copies the histogram for JSON export
+func (h *histogram) snapshot() histogramJSON {
+	counts := make([]int64, len(h.counts))
+	for i := range h.counts {
+		counts[i] = h.counts[i].Load()
+	}
+	return histogramJSON{
+		Bounds: h.bounds,
+		Counts: counts,
+		Count:  h.count.Load(),
+		Sum:    math.Float64frombits(h.sum.Load()),
+		Max:    math.Float64frombits(h.max.Load()),
+	}
+}
+
+// relayHistograms holds the opt-in distributions exposed by GetHistograms
+type relayHistograms struct {
+	enabled atomic.Bool
+	// RTT of ping round trips to the server
+	rttMs *histogram
+	// Time to hand one chunk from a target connection to the QUIC stream
+	uplinkSendMs *histogram
+	// Time to write one chunk from the server to a target connection
+	downlinkWriteMs *histogram
+	// Sizes of relayed chunks in either direction
+	chunkBytes *histogram
+}
+
+// newRelayHistograms returns empty, disabled histograms
+func newRelayHistograms() *relayHistograms {
+	return &relayHistograms{
+		rttMs:           newHistogram(latencyBoundsMs),
+		uplinkSendMs:    newHistogram(latencyBoundsMs),
+		downlinkWriteMs: newHistogram(latencyBoundsMs),
+		chunkBytes:      newHistogram(chunkBoundsB),
+	}
+}
+
+// SetHistogramsEnabled turns recording of GetHistograms distributions on or off
+// Recording is off by default; disabling keeps the samples gathered so far.
+func (c *Client) SetHistogramsEnabled(enabled bool) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.HistogramsEnabled = enabled
+	})
+	c.hist.enabled.Store(enabled)
+}
+
+// GetHistograms returns relay latency, chunk size and RTT histograms as JSON
+// Each histogram lists bucket upper bounds and counts, with one extra
+// overflow count for samples above the last bound. Latencies are in
+// milliseconds, chunk sizes in bytes. Empty unless SetHistogramsEnabled(true).
+func (c *Client) GetHistograms() string {
+	data, err := json.Marshal(map[string]histogramJSON{
+		"rttMs":           c.hist.rttMs.snapshot(),
+		"uplinkSendMs":    c.hist.uplinkSendMs.snapshot(),
+		"downlinkWriteMs": c.hist.downlinkWriteMs.snapshot(),
+		"chunkBytes":      c.hist.chunkBytes.snapshot(),
+	})
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// histogramClock returns the start time for a timed relay operation
+// It is zero while recording is disabled, which the record helpers skip.
+func (c *Client) histogramClock() time.Time {
+	if !c.hist.enabled.Load() {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// recordUplinkSend records a chunk relayed from a target to the server
+func (c *Client) recordUplinkSend(start time.Time, n int) {
+	if start.IsZero() {
+		return
+	}
+	c.hist.uplinkSendMs.record(sinceMillis(start))
+	c.hist.chunkBytes.record(float64(n))
+}
+
+// recordDownlinkWrite records a chunk relayed from the server to a target
+func (c *Client) recordDownlinkWrite(start time.Time, n int) {
+	if start.IsZero() {
+		return
+	}
+	c.hist.downlinkWriteMs.record(sinceMillis(start))
+	c.hist.chunkBytes.record(float64(n))
+}
+
+// recordRTT records a ping round trip
+func (c *Client) recordRTT(rtt time.Duration) {
+	if c.hist.enabled.Load() {
+		c.hist.rttMs.record(float64(rtt.Microseconds()) / 1000)
+	}
+}
+
+// sinceMillis returns the fractional milliseconds elapsed since start
+func sinceMillis(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
@@ -0,0 +1,44 @@
+package vyxclient
+
+import (
+	"fmt"
+	"time"
+)
+
+// reasonIdleTimeout is reported when a connection carried no data for too long
+const reasonIdleTimeout = "idle_timeout"
+
+// SetConnectionIdleTimeout closes Go-side connections idle for idleMillis
+// Data in either direction resets the timer. Applies to connections opened
+// afterwards; 0 disables.
+func (c *Client) SetConnectionIdleTimeout(idleMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.ConnIdleTimeoutMs = idleMillis
+	})
+}
+
+// touch records activity on a connection
+func (cc *Connection) touch() {
+	cc.lastActive.Store(nowMillis())
+}
+
+// idleDeadline returns the read deadline that expires when cc goes idle
+// The zero time means no idle timeout.
+func (cc *Connection) idleDeadline() time.Time {
+	if cc.idleTimeout <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(cc.lastActive.Load()).Add(cc.idleTimeout)
+}
+
+// idleExpired reports whether cc carried no data for its idle timeout
+func (cc *Connection) idleExpired() bool {
+	deadline := cc.idleDeadline()
+	return !deadline.IsZero() && !time.Now().Before(deadline)
+}
+
+// closeIdle closes a connection that went idle
+func (c *Client) closeIdle(cc *Connection, id string) {
+	c.log(fmt.Sprintf("Connection %s idle for %v, closing", id, cc.idleTimeout))
+	c.closeWithReason(id, reasonIdleTimeout)
+}
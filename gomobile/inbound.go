@@ -0,0 +1,39 @@
+package vyxclient
+
+// inboundQueueSize is how many control stream messages may wait for handling
+// Once full the read loop blocks, so backpressure still reaches the server.
+const inboundQueueSize = 1024
+
+// inboundQueue hands control stream messages from the read loop to a
+// dispatcher goroutine, so a slow handler (e.g. a pong write against a full
+// flow control window) doesn't stop the stream from being drained. Messages
+// are handled one at a time in the order they were read. Connection streams
+// have a read loop each and handle messages inline.
+type inboundQueue struct {
+	messages chan *Message
+	done     chan struct{}
+}
+
+// startInbound starts the dispatcher for one control stream session
+func (c *Client) startInbound() *inboundQueue {
+	q := &inboundQueue{
+		messages: make(chan *Message, inboundQueueSize),
+		done:     make(chan struct{}),
+	}
+	go func() {
+		defer close(q.done)
+		for msg := range q.messages {
+			// Like the read loop, stop handling once the client is stopped
+			if c.shouldRun.Load() {
+				c.processMessage(msg)
+			}
+		}
+	}()
+	return q
+}
+
+// stop waits until every queued message has been handled
+func (q *inboundQueue) stop() {
+	close(q.messages)
+	<-q.done
+}
@@ -0,0 +1,74 @@
+package vyxclient
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// reasonLifetimeReached is reported through OnDisconnected on a lifetime refresh
+const reasonLifetimeReached = "Connection lifetime reached"
+
+// SetMaxConnectionLifetime reconnects after lifetimeMillis to get fresh QUIC
+// and TLS keys, e.g. for policies requiring periodic rekeying. When the
+// lifetime is reached the client waits up to drainMillis for open
+// connections to finish, then reconnects. There's a single control
+// connection, so connections still open after the drain are closed and new
+// ones wait for the reconnect. 0 disables (default).
+func (c *Client) SetMaxConnectionLifetime(lifetimeMillis int, drainMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.MaxConnectionLifetimeMs = lifetimeMillis
+		cfg.LifetimeDrainMs = drainMillis
+	})
+}
+
+// startLifetimeTimer arms the lifetime refresh of the current connection
+// Returns a function that disarms it.
+func (c *Client) startLifetimeTimer() func() {
+	lifetime := time.Duration(c.getConfig().MaxConnectionLifetimeMs) * time.Millisecond
+	if lifetime <= 0 {
+		return func() {}
+	}
+
+	c.quicMutex.Lock()
+	conn := c.quicConn
+	c.quicMutex.Unlock()
+
+	done := make(chan struct{})
+	timer := time.AfterFunc(lifetime, func() {
+		c.refreshConnection(conn, lifetime, done)
+	})
+	return func() {
+		timer.Stop()
+		close(done)
+	}
+}
+
+// refreshConnection drains and replaces a connection that reached its lifetime
+// done is closed once the connection has ended for any other reason.
+func (c *Client) refreshConnection(conn *quic.Conn, lifetime time.Duration, done chan struct{}) {
+	c.log(fmt.Sprintf("Connection reached its lifetime of %v, draining", lifetime))
+
+	drain := time.Duration(c.getConfig().LifetimeDrainMs) * time.Millisecond
+	deadline := time.Now().Add(drain)
+	for c.openCount() > 0 && time.Now().Before(deadline) {
+		select {
+		case <-time.After(250 * time.Millisecond):
+		case <-done:
+			return
+		}
+	}
+
+	if n := c.openCount(); n > 0 {
+		c.log(fmt.Sprintf("Drain timed out with %d connections open", n))
+	}
+
+	c.quicMutex.Lock()
+	current := c.quicConn
+	c.quicMutex.Unlock()
+	if current != conn {
+		return
+	}
+	c.requestReconnect(reasonLifetimeReached)
+}
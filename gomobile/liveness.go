@@ -0,0 +1,100 @@
+package vyxclient
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ConnectionLivenessCallback is an optional extension of Callback
+// OnConnectionLiveness fires with the first liveness result of a connection
+// and whenever it changes afterwards.
+type ConnectionLivenessCallback interface {
+	OnConnectionLiveness(id string, alive bool)
+}
+
+// livenessProbes holds the stop channel of each probed connection
+type livenessProbes struct {
+	mu    sync.Mutex
+	probe map[string]chan struct{}
+}
+
+// EnableConnectionLiveness periodically checks that one connection is alive
+// Every intervalMillis a "conn_ping" for id is sent; the server answers
+// "conn_pong" (echoing "ref") if its side of the connection is healthy,
+// anything else or no answer within the interval counts as not alive. This
+// catches a single stalled connection that the connection-level keepalive
+// doesn't. Each probe is a round trip, so enable it only for long-lived
+// connections that need it. Stops when the connection closes.
+// Returns error message or empty string on success
+func (c *Client) EnableConnectionLiveness(id string, intervalMillis int) string {
+	if intervalMillis <= 0 {
+		return "interval must be positive"
+	}
+
+	stop := make(chan struct{})
+
+	c.liveness.mu.Lock()
+	if c.liveness.probe == nil {
+		c.liveness.probe = make(map[string]chan struct{})
+	}
+	if old, ok := c.liveness.probe[id]; ok {
+		close(old)
+	}
+	c.liveness.probe[id] = stop
+	c.liveness.mu.Unlock()
+
+	go c.livenessLoop(id, time.Duration(intervalMillis)*time.Millisecond, stop)
+	return ""
+}
+
+// DisableConnectionLiveness stops probing a connection
+func (c *Client) DisableConnectionLiveness(id string) {
+	c.liveness.mu.Lock()
+	defer c.liveness.mu.Unlock()
+
+	if stop, ok := c.liveness.probe[id]; ok {
+		close(stop)
+		delete(c.liveness.probe, id)
+	}
+}
+
+// livenessLoop probes a connection until stopped
+func (c *Client) livenessLoop(id string, interval time.Duration, stop chan struct{}) {
+	reported := false
+	lastAlive := false
+
+	for {
+		select {
+		case <-time.After(interval):
+		case <-stop:
+			return
+		case <-c.ctx.Done():
+			return
+		}
+
+		if !c.IsConnected() {
+			continue
+		}
+
+		response, err := c.request(&Message{Type: "conn_ping", ID: id}, interval)
+		alive := err == nil && response.Type == "conn_pong"
+		if err != nil {
+			c.log(fmt.Sprintf("Liveness probe for %s failed: %v", id, err))
+		}
+
+		select {
+		case <-stop:
+			return // closed while probing
+		default:
+		}
+
+		if !reported || alive != lastAlive {
+			reported = true
+			lastAlive = alive
+			if cb, ok := c.callback.(ConnectionLivenessCallback); ok {
+				cb.OnConnectionLiveness(id, alive)
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package vyxclient
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// Local socket selection
+//
+// By default the QUIC socket binds to an ephemeral port on all interfaces.
+// SetLocalAddr binds it to one address or interface instead, e.g. so a VPN
+// app can keep its own traffic on the physical network. SetPacketConn goes
+// further and hands the client a ready-made socket. Proxy support is limited
+// to what fits behind a net.PacketConn:
+//
+//   - SOCKS5 with UDP ASSOCIATE works if the app wraps the relay in a
+//     net.PacketConn and passes it to SetPacketConn.
+//   - HTTP CONNECT proxies only carry TCP and can't tunnel QUIC.
+//   - MASQUE (CONNECT-UDP) isn't supported.
+
+// SetLocalAddr binds the QUIC socket to a local address or interface
+// addr is an IP ("192.168.1.5"), an IP and port ("192.168.1.5:40000",
+// "[fe80::1]:0") or an interface name ("wlan0", bound to its first IPv4
+// address, else IPv6). The address family must match the server's. An empty
+// addr restores the default. If a socket exists already it's replaced,
+// dropping the current connection and reconnecting.
+// Returns error message or empty string on success
+func (c *Client) SetLocalAddr(addr string) string {
+	if _, err := resolveLocalAddr(addr); err != nil {
+		return err.Error()
+	}
+
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.LocalAddr = addr
+	})
+	c.replaceTransport("Local address changed")
+	return ""
+}
+
+// SetPacketConn makes QUIC use conn instead of creating its own UDP socket
+// The client takes ownership and closes conn on Stop(). GetSocketFD returns
+// -1 unless conn is a *net.UDPConn, and SetDSCP only applies to those.
+// Passing nil goes back to the client's own socket. A socket in use is
+// replaced, dropping the current connection and reconnecting.
+// Note: This method is not exported for Go Mobile (net.PacketConn can't be bound)
+func (c *Client) SetPacketConn(conn net.PacketConn) {
+	c.transportMutex.Lock()
+	c.customPacketConn = conn
+	c.transportMutex.Unlock()
+	c.replaceTransport("Socket replaced")
+}
+
+// SetProtectedSocket makes QUIC use an app-created UDP socket
+// fd is a UDP socket the app already passed to VpnService.protect(), so
+// the SDK's traffic bypasses the VPN. The client takes ownership of fd and
+// closes it, so on Android pass ParcelFileDescriptor.detachFd().
+// A socket in use is replaced, dropping the current connection and reconnecting.
+// Returns error message or empty string on success
+func (c *Client) SetProtectedSocket(fd int) string {
+	conn, err := packetConnFromFD(fd)
+	if err != nil {
+		return err.Error()
+	}
+	c.SetPacketConn(conn)
+	return ""
+}
+
+// packetConnFromFD wraps an app-created UDP socket, taking ownership of fd
+func packetConnFromFD(fd int) (net.PacketConn, error) {
+	if fd < 0 {
+		return nil, errors.New("invalid file descriptor")
+	}
+
+	file := os.NewFile(uintptr(fd), "protected-udp")
+	if file == nil {
+		return nil, errors.New("invalid file descriptor")
+	}
+	// FilePacketConn works on a duplicate, so fd itself is done with
+	conn, err := net.FilePacketConn(file)
+	file.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to use socket %d: %v", fd, err)
+	}
+	if _, ok := conn.(*net.UDPConn); !ok {
+		conn.Close()
+		return nil, fmt.Errorf("socket %d is not a UDP socket", fd)
+	}
+	return conn, nil
+}
+
+// replaceTransport closes the current socket so the next dial creates a new one
+func (c *Client) replaceTransport(reason string) {
+	c.transportMutex.Lock()
+	exists := c.transport != nil
+	c.transportMutex.Unlock()
+	if !exists {
+		return
+	}
+
+	c.closeTransport()
+	if c.IsConnected() {
+		c.requestReconnect(reason)
+	}
+}
+
+// newPacketConn returns the socket for a new transport
+func (c *Client) newPacketConn() (net.PacketConn, error) {
+	if c.customPacketConn != nil {
+		conn := c.customPacketConn
+		c.customPacketConn = nil
+		return conn, nil
+	}
+
+	laddr, err := resolveLocalAddr(c.getConfig().LocalAddr)
+	if err != nil {
+		return nil, err
+	}
+	udpConn, err := net.ListenUDP("udp", laddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create UDP socket: %w", err)
+	}
+	return udpConn, nil
+}
+
+// resolveLocalAddr parses a SetLocalAddr address, nil meaning the default
+func resolveLocalAddr(addr string) (*net.UDPAddr, error) {
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return nil, nil
+	}
+
+	if ip := net.ParseIP(strings.Trim(addr, "[]")); ip != nil {
+		return &net.UDPAddr{IP: ip}, nil
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil && net.ParseIP(host) != nil {
+		return net.ResolveUDPAddr("udp", addr)
+	}
+
+	iface, err := net.InterfaceByName(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local address %q: not an IP or interface", addr)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read addresses of %s: %w", addr, err)
+	}
+	var v6 net.IP
+	for _, a := range addrs {
+		ipNet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if ip4 := ipNet.IP.To4(); ip4 != nil {
+			return &net.UDPAddr{IP: ip4}, nil
+		}
+		if v6 == nil && !ipNet.IP.IsLinkLocalUnicast() {
+			v6 = ipNet.IP
+		}
+	}
+	if v6 != nil {
+		return &net.UDPAddr{IP: v6}, nil
+	}
+	return nil, fmt.Errorf("interface %s has no usable address", addr)
+}
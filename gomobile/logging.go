@@ -0,0 +1,156 @@
+package vyxclient
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Log levels for SetLogLevel; each level includes the ones before it
+const (
+	LogLevelOff   = 0
+	LogLevelError = 1
+	LogLevelInfo  = 2
+	LogLevelDebug = 3
+)
+
+// SetLogLevel sets the most verbose level passed to OnLog and the system log
+// LogLevelDebug adds message traces and per-attempt logs; the default is
+// LogLevelInfo. Out-of-range levels are clamped.
+func (c *Client) SetLogLevel(level int) {
+	if level < LogLevelOff {
+		level = LogLevelOff
+	}
+	if level > LogLevelDebug {
+		level = LogLevelDebug
+	}
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.LogLevel = level
+	})
+}
+
+// SetSystemLog controls whether log lines also go to Go's log package
+// On Android that is logcat; OnLog still receives them when disabled.
+func (c *Client) SetSystemLog(enabled bool) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.SystemLog = enabled
+	})
+}
+
+// log sends an info message to Android
+func (c *Client) log(message string) {
+	c.logAt(LogLevelInfo, message)
+}
+
+// logError sends a message about a failure that needs attention
+func (c *Client) logError(message string) {
+	c.logAt(LogLevelError, message)
+}
+
+// logDebug sends a trace message, dropped unless debugging
+func (c *Client) logDebug(message string) {
+	c.logAt(LogLevelDebug, message)
+}
+
+// logAt sends message if level is enabled
+func (c *Client) logAt(level int, message string) {
+	cfg := c.getConfig()
+	if level > cfg.LogLevel {
+		return
+	}
+	if cfg.SystemLog {
+		log.Println(message)
+	}
+	if c.callback != nil {
+		c.callback.OnLog(message)
+	}
+}
+
+// logSampler coalesces repetitive log events into periodic summaries
+type logSampler struct {
+	mu      sync.Mutex
+	windows map[string]*sampleWindow
+}
+
+// sampleWindow tracks one event key within the current sampling interval
+type sampleWindow struct {
+	message    string
+	start      time.Time
+	suppressed int
+}
+
+// SetLogSampleInterval controls logging of high-frequency events such as "Received: data"
+// These are only logged at LogLevelDebug.
+// intervalMillis > 0: log the first event, then one summary per interval
+// intervalMillis == 0: log every event
+// intervalMillis < 0: don't log these events at all
+func (c *Client) SetLogSampleInterval(intervalMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.LogSampleIntervalMs = intervalMillis
+	})
+}
+
+// logSampled logs a hot-path event through the sampler
+// key identifies the event, message is what gets logged
+func (c *Client) logSampled(key string, message string) {
+	cfg := c.getConfig()
+	interval := time.Duration(cfg.LogSampleIntervalMs) * time.Millisecond
+	if interval < 0 || cfg.LogLevel < LogLevelDebug {
+		return
+	}
+	if interval == 0 {
+		c.logDebug(message)
+		return
+	}
+
+	now := time.Now()
+
+	c.sampler.mu.Lock()
+	if c.sampler.windows == nil {
+		c.sampler.windows = make(map[string]*sampleWindow)
+	}
+	w, ok := c.sampler.windows[key]
+	if ok && now.Sub(w.start) < interval {
+		w.suppressed++
+		c.sampler.mu.Unlock()
+		return
+	}
+
+	var summary string
+	if ok && w.suppressed > 0 {
+		summary = formatSampleSummary(w, now)
+	}
+	c.sampler.windows[key] = &sampleWindow{message: message, start: now}
+	c.sampler.mu.Unlock()
+
+	if summary != "" {
+		c.logDebug(summary)
+	}
+	c.logDebug(message)
+}
+
+// flushSampledLogs emits summaries for all pending sampled events
+func (c *Client) flushSampledLogs() {
+	now := time.Now()
+
+	c.sampler.mu.Lock()
+	summaries := make([]string, 0, len(c.sampler.windows))
+	for key, w := range c.sampler.windows {
+		if w.suppressed > 0 {
+			summaries = append(summaries, formatSampleSummary(w, now))
+		}
+		delete(c.sampler.windows, key)
+	}
+	c.sampler.mu.Unlock()
+
+	for _, s := range summaries {
+		c.logDebug(s)
+	}
+}
+
+// formatSampleSummary describes the events suppressed in a window
+func formatSampleSummary(w *sampleWindow, now time.Time) string {
+	elapsed := now.Sub(w.start).Round(time.Second)
+	return fmt.Sprintf("%s (x%d more in last %v)", w.message, w.suppressed, elapsed)
+}
@@ -0,0 +1,40 @@
+package vyxclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SetMetadata replaces the device info sent to the server
+// metadata is a JSON string like the one passed to NewClient (battery,
+// network type, app version, ...), empty sends none. The next authentication
+// reports it; if the server accepted the metadata_update capability, a
+// connected client also sends it right away.
+// Returns error message or empty string on success
+func (c *Client) SetMetadata(metadata string) string {
+	if err := validateMetadata(metadata); err != nil {
+		return err.Error()
+	}
+
+	c.serverMutex.Lock()
+	c.metadata = metadata
+	c.serverMutex.Unlock()
+
+	if !c.IsConnected() || !c.hasCap(capMetadataUpdate) {
+		return ""
+	}
+	if err := c.sendMessage(&Message{Type: "metadata_update", Data: metadata}); err != nil {
+		// Still sent with the next authentication
+		c.logError(fmt.Sprintf("Failed to send metadata update: %v", err))
+	}
+	return ""
+}
+
+// validateMetadata checks metadata is empty or well-formed JSON
+func validateMetadata(metadata string) error {
+	if metadata != "" && !json.Valid([]byte(metadata)) {
+		return errors.New("metadata must be valid JSON")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package vyxclient
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// maxEndedMeters bounds how many ended connections keep their byte counts
+const maxEndedMeters = 1024
+
+// connectionBytesJSON is the JSON shape of GetConnectionBytes
+type connectionBytesJSON struct {
+	ID string `json:"id"`
+	// BytesIn were written to the target, BytesOut read from it and sent
+	BytesIn  int64 `json:"bytesIn"`
+	BytesOut int64 `json:"bytesOut"`
+	Open     bool  `json:"open"`
+}
+
+// endedMeters keeps the final byte counts of recently ended connections
+// so they can still be read from the close callbacks.
+type endedMeters struct {
+	mu    sync.Mutex
+	bytes map[string]connectionBytesJSON
+	order []string
+}
+
+// GetConnectionBytes returns the bytes relayed for a Go-side connection as JSON
+// {"id", "bytesIn", "bytesOut", "open"}; bytesIn went to the target, bytesOut
+// came from it. Counts of ended connections stay readable for a while,
+// including from OnConnectionClosed. Returns "" if the connection is unknown.
+func (c *Client) GetConnectionBytes(id string) string {
+	c.clientMutex.RLock()
+	cc, ok := c.clientConns[id]
+	c.clientMutex.RUnlock()
+
+	var counts connectionBytesJSON
+	if ok {
+		counts = connectionBytesJSON{ID: id, BytesIn: cc.written.Load(), BytesOut: cc.sent.Load(), Open: true}
+	} else {
+		c.meters.mu.Lock()
+		counts, ok = c.meters.bytes[id]
+		c.meters.mu.Unlock()
+		if !ok {
+			return ""
+		}
+	}
+
+	data, err := json.Marshal(counts)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// GetTotalBytes returns the bytes relayed for all Go-side connections this session
+// JSON {"bytesIn", "bytesOut"} with the same meaning as GetConnectionBytes.
+func (c *Client) GetTotalBytes() string {
+	data, err := json.Marshal(map[string]int64{
+		"bytesIn":  c.stats.tunnelBytesIn.Load(),
+		"bytesOut": c.stats.tunnelBytesOut.Load(),
+	})
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// countOut records n bytes read from a connection's target and sent
+func (c *Client) countOut(cc *Connection, n int) {
+	cc.sent.Add(int64(n))
+	c.stats.tunnelBytesOut.Add(int64(n))
+}
+
+// countIn records n bytes written to a connection's target
+func (c *Client) countIn(cc *Connection, n int) {
+	cc.written.Add(int64(n))
+	c.stats.tunnelBytesIn.Add(int64(n))
+}
+
+// keepMeter saves the final byte counts of a connection leaving clientConns
+func (c *Client) keepMeter(id string, cc *Connection) {
+	m := &c.meters
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.bytes == nil {
+		m.bytes = make(map[string]connectionBytesJSON)
+	}
+	if _, exists := m.bytes[id]; !exists {
+		m.order = append(m.order, id)
+	}
+	m.bytes[id] = connectionBytesJSON{ID: id, BytesIn: cc.written.Load(), BytesOut: cc.sent.Load()}
+
+	for len(m.order) > maxEndedMeters {
+		delete(m.bytes, m.order[0])
+		m.order = m.order[1:]
+	}
+}
@@ -0,0 +1,162 @@
+package vyxclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// Connection migration
+//
+// QUIC connections are identified by connection IDs rather than addresses,
+// so a connection can move to a new socket when the device changes networks.
+// MigrateConnection opens a socket on the new network, probes the server
+// over it (PATH_CHALLENGE/PATH_RESPONSE) and switches the connection to it.
+// Streams, tunneled connections and the session survive; only packets in
+// flight during the switch are retransmitted.
+//
+// The server must allow it: it must not send the disable_active_migration
+// transport parameter (quic-go's Config.DisableActiveMigration), must answer
+// path challenges from new addresses, and must have issued spare connection
+// IDs (active_connection_id_limit of at least 2) so the new path can use a
+// fresh one. quic-go servers do all of this by default. A load balancer in
+// front of the server has to route by connection ID, not by client address
+// and port, or the new path lands on a server that doesn't know it.
+
+// defaultMigrationTimeout is how long MigrateConnection probes the new path
+const defaultMigrationTimeout = 3 * time.Second
+
+// SetConnectionMigration enables or disables MigrateConnection (default on)
+// When disabled, MigrateConnection behaves like ForceReconnect. Turn it off
+// if the server or its load balancer can't handle migration.
+func (c *Client) SetConnectionMigration(enabled bool) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.ConnectionMigration = enabled
+	})
+}
+
+// MigrateConnection moves the connection to a new socket after a network change
+// Call it from the app's ConnectivityManager callback instead of
+// ForceReconnect. fd is a UDP socket the app created on the new network and
+// passed to VpnService.protect() (the client takes ownership, so pass
+// ParcelFileDescriptor.detachFd()), or -1 to let the client create one.
+// The new path is probed for up to timeoutMillis (0 picks 3000); once the
+// server answers, traffic switches over and tunneled connections are kept.
+// If migration is disabled, the client isn't connected, the server refuses
+// migration or the probe fails, the client falls back to ForceReconnect.
+// Returns error message or empty string if the connection migrated
+func (c *Client) MigrateConnection(fd int, timeoutMillis int) string {
+	if timeoutMillis < 0 {
+		return "timeout must not be negative"
+	}
+	timeout := defaultMigrationTimeout
+	if timeoutMillis > 0 {
+		timeout = time.Duration(timeoutMillis) * time.Millisecond
+	}
+
+	var packetConn net.PacketConn
+	if fd >= 0 {
+		conn, err := packetConnFromFD(fd)
+		if err != nil {
+			return err.Error()
+		}
+		packetConn = conn
+	}
+
+	c.migrationMutex.Lock()
+	defer c.migrationMutex.Unlock()
+
+	if err := c.migrate(packetConn, timeout); err != nil {
+		c.stats.migrationFailures.Add(1)
+		if c.ctx.Err() == nil {
+			c.log(fmt.Sprintf("Connection migration failed: %v, reconnecting", err))
+			c.ForceReconnect()
+		}
+		return err.Error()
+	}
+	c.stats.migrations.Add(1)
+	return ""
+}
+
+// migrate probes a path over packetConn (nil for a new socket) and switches to it
+// packetConn is closed on failure.
+func (c *Client) migrate(packetConn net.PacketConn, timeout time.Duration) error {
+	closeSocket := func() {
+		if packetConn != nil {
+			packetConn.Close()
+		}
+	}
+
+	if !c.getConfig().ConnectionMigration {
+		closeSocket()
+		return errors.New("connection migration disabled")
+	}
+
+	c.quicMutex.Lock()
+	conn := c.quicConn
+	c.quicMutex.Unlock()
+	if conn == nil || !c.IsConnected() {
+		closeSocket()
+		return errors.New("not connected")
+	}
+
+	if packetConn == nil {
+		c.transportMutex.Lock()
+		newConn, err := c.newPacketConn()
+		c.transportMutex.Unlock()
+		if err != nil {
+			return err
+		}
+		packetConn = newConn
+	}
+	c.applySocketOptions(packetConn)
+
+	transport := &quic.Transport{Conn: packetConn}
+	abandon := func() {
+		transport.Close()
+		packetConn.Close()
+	}
+
+	path, err := conn.AddPath(transport)
+	if err != nil {
+		abandon()
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, timeout)
+	defer cancel()
+	err = path.Probe(ctx)
+	if err == nil {
+		err = path.Switch()
+	}
+	if err != nil {
+		path.Close()
+		abandon()
+		return fmt.Errorf("path probe failed: %w", err)
+	}
+
+	// The connection may have dropped, or the socket been replaced, meanwhile
+	c.transportMutex.Lock()
+	oldTransport, oldConn := c.transport, c.packetConn
+	if oldTransport == nil || conn.Context().Err() != nil {
+		c.transportMutex.Unlock()
+		abandon()
+		return errors.New("connection closed during migration")
+	}
+	c.transport, c.packetConn = transport, packetConn
+	c.transportMutex.Unlock()
+
+	// Closing a transport kills its connections, so the old socket stays
+	// open until the migrated connection ends
+	context.AfterFunc(conn.Context(), func() {
+		oldTransport.Close()
+		oldConn.Close()
+	})
+
+	c.log(fmt.Sprintf("Migrated QUIC connection to %s", packetConn.LocalAddr()))
+	return nil
+}
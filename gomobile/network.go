@@ -0,0 +1,117 @@
+package vyxclient
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// Network types accepted by SetNetworkType
+const (
+	NetworkTypeUnknown  = "unknown"
+	NetworkTypeWifi     = "wifi"
+	NetworkTypeCellular = "cellular"
+	NetworkTypeEthernet = "ethernet"
+)
+
+// networkProfile holds the QUIC timers used on a network type
+type networkProfile struct {
+	idleTimeout     time.Duration
+	keepAlivePeriod time.Duration
+}
+
+// defaultKeepAlivePeriod keeps carrier NAT bindings, which commonly expire
+// after ~30s of silence, alive when the network type is unknown
+const defaultKeepAlivePeriod = 20 * time.Second
+
+// networkProfiles maps network types to their QUIC timers
+// Wi-Fi and Ethernet are cheap to keep awake but a short idle timeout
+// detects dead paths quickly. Cellular radios are expensive to wake, so
+// keepalives are only frequent enough to hold carrier NAT bindings open and
+// the idle timeout is long enough to ride out radio dormancy.
+var networkProfiles = map[string]networkProfile{
+	NetworkTypeUnknown:  {idleTimeout: 30 * time.Second, keepAlivePeriod: defaultKeepAlivePeriod},
+	NetworkTypeWifi:     {idleTimeout: 30 * time.Second, keepAlivePeriod: 15 * time.Second},
+	NetworkTypeEthernet: {idleTimeout: 30 * time.Second, keepAlivePeriod: 15 * time.Second},
+	NetworkTypeCellular: {idleTimeout: 120 * time.Second, keepAlivePeriod: 25 * time.Second},
+}
+
+// SetNetworkType tells the client which network it's on
+// networkType is "wifi", "cellular", "ethernet" or "unknown". QUIC fixes the
+// idle timeout during the handshake, so the new timers apply from the next
+// connection; call this from the app's network callback before reconnecting.
+// Returns error message or empty string on success
+func (c *Client) SetNetworkType(networkType string) string {
+	if _, ok := networkProfiles[networkType]; !ok {
+		return fmt.Sprintf("unknown network type %q", networkType)
+	}
+
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.NetworkType = networkType
+	})
+	c.log(fmt.Sprintf("Network type set to %s", networkType))
+	return ""
+}
+
+// SetQUICTimeouts overrides the network profile's QUIC timers
+// idleTimeoutMillis closes the connection after that much silence and
+// keepAliveMillis sends transport keepalives that often, which is what
+// refreshes NAT bindings; 0 keeps the network profile's value for either.
+// Applies from the next connection. Returns error message or empty string on success
+func (c *Client) SetQUICTimeouts(idleTimeoutMillis int, keepAliveMillis int) string {
+	cfg := c.getConfig()
+	cfg.QUICIdleTimeoutMs = idleTimeoutMillis
+	cfg.QUICKeepAlivePeriodMs = keepAliveMillis
+	if err := validateQUICTimeouts(cfg); err != nil {
+		return err.Error()
+	}
+
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.QUICIdleTimeoutMs = idleTimeoutMillis
+		cfg.QUICKeepAlivePeriodMs = keepAliveMillis
+	})
+	return ""
+}
+
+// quicTimers returns the idle timeout and keepalive period for cfg
+// Explicit overrides win over the network type's profile.
+func quicTimers(cfg clientConfig) (time.Duration, time.Duration) {
+	profile, ok := networkProfiles[cfg.NetworkType]
+	if !ok {
+		profile = networkProfiles[NetworkTypeUnknown]
+	}
+
+	idle, keepAlive := profile.idleTimeout, profile.keepAlivePeriod
+	if cfg.QUICIdleTimeoutMs > 0 {
+		idle = time.Duration(cfg.QUICIdleTimeoutMs) * time.Millisecond
+	}
+	if cfg.QUICKeepAlivePeriodMs > 0 {
+		keepAlive = time.Duration(cfg.QUICKeepAlivePeriodMs) * time.Millisecond
+	}
+	return idle, keepAlive
+}
+
+// validateQUICTimeouts checks that keepalives fire before the idle timeout
+func validateQUICTimeouts(cfg clientConfig) error {
+	if cfg.QUICIdleTimeoutMs < 0 || cfg.QUICKeepAlivePeriodMs < 0 {
+		return errors.New("quic timeouts must not be negative")
+	}
+	idle, keepAlive := quicTimers(cfg)
+	if keepAlive >= idle {
+		return fmt.Errorf("keepalive period %v must be shorter than idle timeout %v", keepAlive, idle)
+	}
+	return nil
+}
+
+// buildQUICConfig returns the QUIC settings for the current network type
+func (c *Client) buildQUICConfig() *quic.Config {
+	idle, keepAlive := quicTimers(c.getConfig())
+
+	return &quic.Config{
+		Versions:        offeredQUICVersions,
+		MaxIdleTimeout:  idle,
+		KeepAlivePeriod: keepAlive,
+	}
+}
@@ -0,0 +1,103 @@
+package vyxclient
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+)
+
+// observerQueueSize bounds the events buffered for a slow Observer
+const observerQueueSize = 1024
+
+// Observer event types
+const (
+	EventConnecting         = "connecting"
+	EventConnected          = "connected"
+	EventDisconnected       = "disconnected"
+	EventConnectFailed      = "connect_failed"
+	EventReconnectScheduled = "reconnect_scheduled"
+	EventConnectionOpened   = "connection_opened"
+	EventConnectionClosed   = "connection_closed"
+	EventMessageSent        = "message_sent"
+	EventMessageReceived    = "message_received"
+	EventError              = "error"
+)
+
+// Observer receives every significant internal event, for debug tooling
+// Unlike Callback it can't influence the client. Events are delivered in
+// order on a dedicated goroutine; if the observer falls behind, events are
+// dropped rather than blocking the client, and the next delivered event
+// carries the number dropped in its "dropped" field.
+type Observer interface {
+	// OnEvent receives the event time (Unix ms), one of the Event* types and
+	// a JSON object with the event's details
+	OnEvent(timestampMillis int64, eventType string, detailJSON string)
+}
+
+// observerEvent is a queued event
+type observerEvent struct {
+	at        time.Time
+	eventType string
+	detail    map[string]interface{}
+}
+
+// observerSink delivers events to one Observer
+type observerSink struct {
+	observer Observer
+	events   chan observerEvent
+	done     chan struct{}
+	dropped  atomic.Int64
+}
+
+// SetObserver installs an Observer, nil removes it
+func (c *Client) SetObserver(observer Observer) {
+	var sink *observerSink
+	if observer != nil {
+		sink = &observerSink{
+			observer: observer,
+			events:   make(chan observerEvent, observerQueueSize),
+			done:     make(chan struct{}),
+		}
+		go sink.run()
+	}
+
+	if old := c.observer.Swap(sink); old != nil {
+		close(old.done)
+	}
+}
+
+// observe queues an event for the Observer without blocking
+func (c *Client) observe(eventType string, detail map[string]interface{}) {
+	sink := c.observer.Load()
+	if sink == nil {
+		return
+	}
+
+	select {
+	case sink.events <- observerEvent{at: time.Now(), eventType: eventType, detail: detail}:
+	default:
+		sink.dropped.Add(1)
+	}
+}
+
+// run delivers queued events until the sink is replaced
+func (s *observerSink) run() {
+	for {
+		select {
+		case ev := <-s.events:
+			if ev.detail == nil {
+				ev.detail = make(map[string]interface{})
+			}
+			if dropped := s.dropped.Swap(0); dropped > 0 {
+				ev.detail["dropped"] = dropped
+			}
+			data, err := json.Marshal(ev.detail)
+			if err != nil {
+				continue
+			}
+			s.observer.OnEvent(ev.at.UnixMilli(), ev.eventType, string(data))
+		case <-s.done:
+			return
+		}
+	}
+}
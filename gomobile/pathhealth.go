@@ -0,0 +1,150 @@
+package vyxclient
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// Path issue directions reported to OnPathIssue
+const (
+	PathDirectionUplink   = "uplink"
+	PathDirectionDownlink = "downlink"
+	PathDirectionNone     = "none"
+)
+
+// PathIssueCallback is an optional extension of Callback
+// OnPathIssue fires when one direction of the path to the server appears
+// broken ("uplink" or "downlink") and with "none" once it recovers.
+type PathIssueCallback interface {
+	OnPathIssue(direction string)
+}
+
+// pathMonitor holds directional liveness state between checks
+type pathMonitor struct {
+	mu sync.Mutex
+	// conn is the QUIC connection the packet baseline belongs to
+	conn            *quic.Conn
+	packetsSent     uint64
+	packetsReceived uint64
+	issue           string
+}
+
+// SetPathMonitor enables directional liveness checks on the server path
+// Every interval the SDK compares QUIC packets sent and received and sends a
+// ping; a failed ping while packets still arrive points at the uplink, one
+// with nothing arriving at the downlink. intervalMillis <= 0 disables.
+func (c *Client) SetPathMonitor(intervalMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.PathCheckIntervalMs = intervalMillis
+	})
+}
+
+// pathMonitorLoop runs directional checks while the client is running
+func (c *Client) pathMonitorLoop() {
+	for c.shouldRun.Load() {
+		interval := time.Duration(c.getConfig().PathCheckIntervalMs) * time.Millisecond
+		if interval <= 0 {
+			interval = 5 * time.Second // re-check config periodically
+			c.resetPathMonitor()
+		} else if c.IsConnected() {
+			c.checkPath(interval)
+		} else {
+			c.resetPathMonitor()
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// checkPath classifies the path from packet deltas and a ping round trip
+func (c *Client) checkPath(interval time.Duration) {
+	timeout := interval / 2
+	if timeout > 5*time.Second {
+		timeout = 5 * time.Second
+	}
+	pingOK := c.PingSync(int(timeout.Milliseconds())) >= 0
+
+	c.quicMutex.Lock()
+	conn := c.quicConn
+	c.quicMutex.Unlock()
+	if conn == nil {
+		return
+	}
+	qs := conn.ConnectionStats()
+
+	c.path.mu.Lock()
+	sent := qs.PacketsSent - c.path.packetsSent
+	received := qs.PacketsReceived - c.path.packetsReceived
+	first := c.path.conn != conn
+	c.path.conn = conn
+	c.path.packetsSent = qs.PacketsSent
+	c.path.packetsReceived = qs.PacketsReceived
+	c.path.mu.Unlock()
+
+	if first {
+		// No baseline yet for the packet deltas
+		return
+	}
+
+	direction := PathDirectionNone
+	if !pingOK {
+		switch {
+		case received > 0:
+			// The server still reaches us but our ping went unanswered
+			direction = PathDirectionUplink
+		case sent > 0:
+			// We keep sending yet nothing at all comes back
+			direction = PathDirectionDownlink
+		}
+	}
+	c.setPathIssue(direction)
+}
+
+// setPathIssue records direction and notifies the app when it changes
+func (c *Client) setPathIssue(direction string) {
+	c.path.mu.Lock()
+	previous := c.path.issue
+	if previous == "" {
+		previous = PathDirectionNone
+	}
+	c.path.issue = direction
+	c.path.mu.Unlock()
+
+	if direction == previous {
+		return
+	}
+	if direction == PathDirectionNone {
+		c.log("Server path recovered")
+	} else {
+		c.log(fmt.Sprintf("Asymmetric path failure detected: %s", direction))
+	}
+	if cb, ok := c.callback.(PathIssueCallback); ok {
+		cb.OnPathIssue(direction)
+	}
+}
+
+// resetPathMonitor drops the baseline, e.g. after the connection changes
+// An outstanding issue is cleared silently since the old path is gone.
+func (c *Client) resetPathMonitor() {
+	c.path.mu.Lock()
+	c.path.conn = nil
+	c.path.issue = ""
+	c.path.mu.Unlock()
+}
+
+// currentPathIssue returns the direction of the outstanding path issue, "" if none
+func (c *Client) currentPathIssue() string {
+	c.path.mu.Lock()
+	defer c.path.mu.Unlock()
+	if c.path.issue == PathDirectionNone {
+		return ""
+	}
+	return c.path.issue
+}
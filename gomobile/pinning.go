@@ -0,0 +1,72 @@
+package vyxclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// errPinMismatch means the server's leaf certificate doesn't match the pin
+var errPinMismatch = errors.New("server certificate does not match pinned SHA-256")
+
+// SetPinnedCertSHA256 pins the server's leaf certificate
+// hexFingerprint is the SHA-256 of the certificate's DER encoding, hex encoded
+// (colons and case are ignored). The pin is required in addition to normal
+// chain verification; an empty fingerprint removes the pin. Takes effect on
+// the next connect. Returns error message or empty string on success.
+func (c *Client) SetPinnedCertSHA256(hexFingerprint string) string {
+	pin := normalizeFingerprint(hexFingerprint)
+	if _, err := decodePin(pin); err != nil {
+		return err.Error()
+	}
+
+	changed := false
+	c.updateConfig(func(cfg *clientConfig) {
+		changed = cfg.PinnedCertSHA256 != pin
+		cfg.PinnedCertSHA256 = pin
+	})
+	if changed {
+		// Resumed sessions skip certificate checks, so don't let tickets from
+		// before the pin changed bypass it
+		c.sessions.clear()
+	}
+	return ""
+}
+
+// normalizeFingerprint strips separators and lowercases a hex fingerprint
+func normalizeFingerprint(fingerprint string) string {
+	fingerprint = strings.TrimSpace(fingerprint)
+	fingerprint = strings.ReplaceAll(fingerprint, ":", "")
+	return strings.ToLower(fingerprint)
+}
+
+// decodePin parses a normalized fingerprint, nil for no pin
+func decodePin(pin string) ([]byte, error) {
+	if pin == "" {
+		return nil, nil
+	}
+	raw, err := hex.DecodeString(pin)
+	if err != nil || len(raw) != sha256.Size {
+		return nil, fmt.Errorf("pinnedCertSHA256 must be %d hex-encoded bytes", sha256.Size)
+	}
+	return raw, nil
+}
+
+// pinVerifier returns a VerifyPeerCertificate callback checking the leaf against pin
+// It runs after standard chain verification, so both must pass.
+func pinVerifier(pin []byte) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errPinMismatch
+		}
+		sum := sha256.Sum256(rawCerts[0])
+		if !bytes.Equal(sum[:], pin) {
+			return errPinMismatch
+		}
+		return nil
+	}
+}
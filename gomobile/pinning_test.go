@@ -0,0 +1,110 @@
+package vyxclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// selfSignedCert returns a certificate for "localhost" and a pool trusting it
+func selfSignedCert(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+// handshake runs a TLS handshake over loopback TCP, returning the client's error
+func handshake(t *testing.T, serverConf, clientConf *tls.Config) error {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		server := tls.Server(conn, serverConf)
+		server.Handshake()
+		server.Close()
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return tls.Client(conn, clientConf).Handshake()
+}
+
+func TestPinVerifier(t *testing.T) {
+	cert, pool := selfSignedCert(t)
+	serverConf := &tls.Config{Certificates: []tls.Certificate{cert}}
+	match := sha256.Sum256(cert.Certificate[0])
+	mismatch := sha256.Sum256([]byte("some other certificate"))
+
+	tests := []struct {
+		name    string
+		pin     []byte
+		wantErr bool
+	}{
+		{"matching pin", match[:], false},
+		{"mismatched pin", mismatch[:], true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientConf := &tls.Config{
+				RootCAs:               pool,
+				ServerName:            "localhost",
+				VerifyPeerCertificate: pinVerifier(tt.pin),
+			}
+			err := handshake(t, serverConf, clientConf)
+			if tt.wantErr {
+				if !errors.Is(err, errPinMismatch) {
+					t.Fatalf("handshake error = %v, want %v", err, errPinMismatch)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("handshake failed: %v", err)
+			}
+		})
+	}
+}
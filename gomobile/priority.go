@@ -0,0 +1,247 @@
+package vyxclient
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Connection priorities for OpenConnection
+//
+// Writes to the QUIC stream are scheduled in three tiers:
+// control (auth, pong, close, ...) > interactive > bulk.
+// Whenever the stream frees up, the highest-priority waiting sender goes next;
+// senders of equal priority are served in no particular order. A write that
+// has already started is never preempted, and sustained higher-priority
+// traffic can starve bulk data.
+const (
+	PriorityInteractive = 1
+	PriorityBulk        = 2
+)
+
+// priorityControl is used for all non-data messages
+const priorityControl = 0
+
+const numPriorities = 3
+
+// sendScheduler serializes stream writes, granting the stream to the highest-priority waiter
+type sendScheduler struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	busy    bool
+	waiting [numPriorities]int
+}
+
+// acquire blocks until the caller may write at the given priority
+func (s *sendScheduler) acquire(priority int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cond == nil {
+		s.cond = sync.NewCond(&s.mu)
+	}
+
+	// Out-of-range priorities would index past waiting
+	if priority < priorityControl {
+		priority = priorityControl
+	} else if priority > PriorityBulk {
+		priority = PriorityBulk
+	}
+
+	s.waiting[priority]++
+	for s.busy || s.higherWaiting(priority) {
+		s.cond.Wait()
+	}
+	s.waiting[priority]--
+	s.busy = true
+}
+
+// release hands the stream to the next waiter
+func (s *sendScheduler) release() {
+	s.mu.Lock()
+	s.busy = false
+	if s.cond != nil {
+		s.cond.Broadcast()
+	}
+	s.mu.Unlock()
+}
+
+// idle reports whether no sender is writing or waiting
+func (s *sendScheduler) idle() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.busy {
+		return false
+	}
+	for _, n := range s.waiting {
+		if n > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// higherWaiting reports whether a sender with higher priority is queued
+func (s *sendScheduler) higherWaiting(priority int) bool {
+	for p := 0; p < priority; p++ {
+		if s.waiting[p] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ConnectionOptions configures a connection opened with OpenConnectionWithOptions
+type ConnectionOptions struct {
+	// Priority is PriorityInteractive (default when 0) or PriorityBulk
+	Priority int
+	// ProxyProtocol selects a PROXY protocol header sent as the first data:
+	// ProxyProtocolNone, ProxyProtocolV1 or ProxyProtocolV2
+	ProxyProtocol int
+	// SourceAddr is the original client "ip:port" carried in the PROXY header
+	SourceAddr string
+	// DestAddr is the destination "ip:port" in the PROXY header, defaults to addr
+	DestAddr string
+	// TTLMillis force-closes the connection after this long, 0 uses the client default
+	TTLMillis int
+	// KeyBase64 encrypts the connection's data with its own key, see SetConnectionKey
+	KeyBase64 string
+}
+
+// OpenConnection asks the server to open a connection to addr and returns its ID
+// addr may be a comma-separated list of candidates, tried in order by the server.
+// priority: PriorityInteractive or PriorityBulk, carried in the "connect" message
+// and used to schedule this connection's outgoing data locally.
+// Data for the connection is sent with SendMessage("data", id, ...) and arrives via OnMessage.
+func (c *Client) OpenConnection(addr string, priority int) (string, error) {
+	return c.OpenConnectionWithOptions(addr, &ConnectionOptions{Priority: priority})
+}
+
+// OpenConnectionWithOptions is OpenConnection with per-connection options
+// opts may be nil for defaults.
+func (c *Client) OpenConnectionWithOptions(addr string, opts *ConnectionOptions) (string, error) {
+	if c.stopping.Load() {
+		return "", errShuttingDown
+	}
+	if opts == nil {
+		opts = &ConnectionOptions{}
+	}
+
+	priority := opts.Priority
+	if priority == 0 {
+		priority = PriorityInteractive
+	}
+	if priority != PriorityInteractive && priority != PriorityBulk {
+		return "", fmt.Errorf("invalid priority: %d", priority)
+	}
+
+	var proxyHeader []byte
+	if opts.ProxyProtocol != ProxyProtocolNone {
+		dst := opts.DestAddr
+		if dst == "" {
+			if candidates := splitAddrs(addr); len(candidates) > 0 {
+				dst = candidates[0]
+			}
+		}
+		header, err := buildProxyHeader(opts.ProxyProtocol, opts.SourceAddr, dst)
+		if err != nil {
+			return "", err
+		}
+		proxyHeader = header
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate connection id: %w", err)
+	}
+
+	// Honor server-requested pacing of new connections
+	release, err := c.acquireEstablishSlot(c.ctx, priority)
+	if err != nil {
+		return "", err
+	}
+	release()
+
+	if opts.KeyBase64 != "" {
+		if errMsg := c.SetConnectionKey(id, opts.KeyBase64); errMsg != "" {
+			return "", errors.New(errMsg)
+		}
+	}
+
+	c.setConnectionPriority(id, priority)
+
+	if err := c.sendMessage(&Message{Type: "connect", ID: id, Addr: addr, Priority: priority}); err != nil {
+		c.forgetConnectionPriority(id)
+		c.forgetConnectionKey(id)
+		return "", err
+	}
+	c.stats.connectionsOpened.Add(1)
+	c.trackOpen(id, addr)
+	c.observe(EventConnectionOpened, map[string]interface{}{"id": id, "addr": addr, "origin": "client"})
+
+	ttl := opts.TTLMillis
+	if ttl == 0 {
+		ttl = c.getConfig().DefaultConnectionTTLMs
+	}
+	c.startTTL(id, time.Duration(ttl)*time.Millisecond)
+
+	if proxyHeader != nil {
+		encoded := base64.StdEncoding.EncodeToString(proxyHeader)
+		if err := c.sendMessage(&Message{Type: "data", ID: id, Data: encoded}); err != nil {
+			// The server has the connection but the caller gets no ID, so undo it
+			c.sendMessage(&Message{Type: "close", ID: id})
+			c.markClosed(id)
+			c.connectionEnded(id)
+			return "", fmt.Errorf("failed to send PROXY header: %w", err)
+		}
+	}
+
+	return id, nil
+}
+
+// setConnectionPriority records the scheduling priority of a connection
+// Anything but PriorityBulk is stored as PriorityInteractive, so data never
+// lands in the control tier.
+func (c *Client) setConnectionPriority(id string, priority int) {
+	if priority != PriorityBulk {
+		priority = PriorityInteractive
+	}
+
+	c.priorityMutex.Lock()
+	defer c.priorityMutex.Unlock()
+
+	if c.connPriority == nil {
+		c.connPriority = make(map[string]int)
+	}
+	c.connPriority[id] = priority
+}
+
+// forgetConnectionPriority drops the priority of a closed connection
+func (c *Client) forgetConnectionPriority(id string) {
+	c.priorityMutex.Lock()
+	delete(c.connPriority, id)
+	c.priorityMutex.Unlock()
+}
+
+// messagePriority returns the scheduling tier for an outgoing message
+func (c *Client) messagePriority(msg *Message) int {
+	if msg.Type != "data" {
+		return priorityControl
+	}
+
+	return c.connectionPriority(msg.ID)
+}
+
+// connectionPriority returns the scheduling priority recorded for id
+func (c *Client) connectionPriority(id string) int {
+	c.priorityMutex.Lock()
+	defer c.priorityMutex.Unlock()
+
+	if p, ok := c.connPriority[id]; ok {
+		return p
+	}
+	return PriorityInteractive
+}
@@ -0,0 +1,60 @@
+package vyxclient
+
+import (
+	"fmt"
+)
+
+// Protocol versions
+// The client sends its version in the auth message "proto"; the server
+// answers with its own in auth_success. Servers that predate versioning
+// don't send one and are treated as protocolLegacy.
+const (
+	protocolLegacy  = 1
+	protocolVersion = 2
+)
+
+// messageMinVersion is the protocol version that introduced each
+// client-to-server message type beyond the legacy set (auth, connected,
+// data, close, pong)
+var messageMinVersion = map[string]int{
+	"connect":                  2,
+	"connect_result":           2,
+	"connect_result_with_data": 2,
+	"conn_ping":                2,
+	"ping":                     2,
+	"redirect_ack":             2,
+	"conn_resume":              2,
+}
+
+// GetProtocolVersion returns the protocol version agreed with the server
+// Returns 0 before the first successful authentication.
+func (c *Client) GetProtocolVersion() int {
+	return int(c.protoVersion.Load())
+}
+
+// setServerProtocol records the version negotiated from the server's answer
+func (c *Client) setServerProtocol(serverVersion int) {
+	negotiated := serverVersion
+	if negotiated <= 0 {
+		negotiated = protocolLegacy
+	}
+	if negotiated > protocolVersion {
+		negotiated = protocolVersion
+	}
+
+	if int(c.protoVersion.Swap(int32(negotiated))) != negotiated {
+		c.log(fmt.Sprintf("Using protocol version %d", negotiated))
+	}
+}
+
+// checkMessageSupported rejects messages the server's protocol version lacks
+func (c *Client) checkMessageSupported(msgType string) error {
+	negotiated := c.GetProtocolVersion()
+	if negotiated == 0 {
+		return nil
+	}
+	if min, ok := messageMinVersion[msgType]; ok && negotiated < min {
+		return fmt.Errorf("server protocol version %d does not support %q messages", negotiated, msgType)
+	}
+	return nil
+}
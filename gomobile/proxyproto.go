@@ -0,0 +1,94 @@
+package vyxclient
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// PROXY protocol support
+//
+// OpenConnectionWithOptions can prepend a PROXY protocol header
+// (https://www.haproxy.org/download/2.8/doc/proxy-protocol.txt) as the first
+// bytes of a connection's data, so the proxy terminating it on the server
+// side learns the original source address.
+//   - v1: human-readable line, "PROXY TCP4 <src> <dst> <sport> <dport>\r\n"
+//   - v2: binary header with the 12-byte signature, PROXY command, TCP over IPv4/IPv6
+// Both versions only carry TCP addresses; no TLVs are sent in v2. When the
+// source or destination isn't a literal IP:port, v1 sends "PROXY UNKNOWN"
+// and v2 sends the LOCAL command, which tells the receiver to use the real
+// connection endpoints.
+
+// Values for ConnectionOptions.ProxyProtocol
+const (
+	ProxyProtocolNone = 0
+	ProxyProtocolV1   = 1
+	ProxyProtocolV2   = 2
+)
+
+// proxyV2Signature starts every PROXY protocol v2 header
+var proxyV2Signature = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
+
+// buildProxyHeader builds a PROXY protocol header for the given endpoints
+func buildProxyHeader(version int, src string, dst string) ([]byte, error) {
+	srcIP, srcPort, srcOK := parseIPPort(src)
+	dstIP, dstPort, dstOK := parseIPPort(dst)
+	known := srcOK && dstOK && (srcIP.To4() != nil) == (dstIP.To4() != nil)
+
+	switch version {
+	case ProxyProtocolV1:
+		if !known {
+			return []byte("PROXY UNKNOWN\r\n"), nil
+		}
+		proto := "TCP6"
+		if srcIP.To4() != nil {
+			proto = "TCP4"
+		}
+		return []byte(fmt.Sprintf("PROXY %s %s %s %d %d\r\n", proto, srcIP, dstIP, srcPort, dstPort)), nil
+
+	case ProxyProtocolV2:
+		var buf bytes.Buffer
+		buf.Write(proxyV2Signature)
+		if !known {
+			buf.WriteByte(0x20) // version 2, LOCAL
+			buf.WriteByte(0x00) // AF_UNSPEC
+			binary.Write(&buf, binary.BigEndian, uint16(0))
+			return buf.Bytes(), nil
+		}
+
+		buf.WriteByte(0x21) // version 2, PROXY
+		if src4, dst4 := srcIP.To4(), dstIP.To4(); src4 != nil {
+			buf.WriteByte(0x11) // TCP over IPv4
+			binary.Write(&buf, binary.BigEndian, uint16(12))
+			buf.Write(src4)
+			buf.Write(dst4)
+		} else {
+			buf.WriteByte(0x21) // TCP over IPv6
+			binary.Write(&buf, binary.BigEndian, uint16(36))
+			buf.Write(srcIP.To16())
+			buf.Write(dstIP.To16())
+		}
+		binary.Write(&buf, binary.BigEndian, uint16(srcPort))
+		binary.Write(&buf, binary.BigEndian, uint16(dstPort))
+		return buf.Bytes(), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported PROXY protocol version: %d", version)
+	}
+}
+
+// parseIPPort parses a literal "ip:port"
+func parseIPPort(addr string) (net.IP, int, bool) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, 0, false
+	}
+	ip := net.ParseIP(host)
+	port, err := strconv.Atoi(portStr)
+	if ip == nil || err != nil || port < 0 || port > 65535 {
+		return nil, 0, false
+	}
+	return ip, port, true
+}
@@ -0,0 +1,112 @@
+package vyxclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Bandwidth limiting
+//
+// Relayed connections pass through two token buckets: their own
+// (SetConnectionRateLimit) and one shared by all connections (SetRateLimit).
+// Both directions of a connection draw from the same buckets. A chunk waits
+// for the per-connection bucket first and then the global one; since both
+// refill while waiting, a connection effectively runs at the stricter of the
+// two rates. The burst size is how many bytes can go through at once after a
+// quiet period, so short interactive exchanges aren't delayed while longer
+// transfers settle at the configured rate.
+
+// tokenBucket is a byte-based token bucket, safe for concurrent use
+// A nil bucket or a zero rate means unlimited.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // bytes per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates a bucket, returns nil when rate is 0 (unlimited)
+// burstBytes <= 0 defaults to one second worth of traffic.
+func newTokenBucket(bytesPerSecond int64, burstBytes int64) *tokenBucket {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	if burstBytes <= 0 {
+		burstBytes = bytesPerSecond
+	}
+	return &tokenBucket{
+		rate:   float64(bytesPerSecond),
+		burst:  float64(burstBytes),
+		tokens: float64(burstBytes),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until n bytes may pass
+// Chunks larger than the burst are let through by going into debt, so
+// every chunk eventually passes at the configured average rate.
+func (b *tokenBucket) wait(ctx context.Context, n int) error {
+	if b == nil || n <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens -= float64(n)
+	deficit := -b.tokens
+	b.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration(deficit / b.rate * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetRateLimit caps the total bandwidth of all relayed connections
+// bytesPerSecond 0 disables the limit; burstBytes 0 defaults to one second of traffic.
+func (c *Client) SetRateLimit(bytesPerSecond int64, burstBytes int64) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.RateLimitBytesPerSec = bytesPerSecond
+		cfg.RateLimitBurstBytes = burstBytes
+	})
+
+	c.limiterMutex.Lock()
+	c.globalLimiter = newTokenBucket(bytesPerSecond, burstBytes)
+	c.limiterMutex.Unlock()
+}
+
+// SetConnectionRateLimit caps the bandwidth of each relayed connection
+// Applies to connections registered after the call.
+func (c *Client) SetConnectionRateLimit(bytesPerSecond int64, burstBytes int64) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.ConnRateLimitBytesPerSec = bytesPerSecond
+		cfg.ConnRateLimitBurstBytes = burstBytes
+	})
+}
+
+// throttle waits until n bytes of cc's traffic may pass both buckets
+func (c *Client) throttle(cc *Connection, n int) error {
+	if err := cc.limiter.wait(cc.ctx, n); err != nil {
+		return err
+	}
+
+	c.limiterMutex.Lock()
+	global := c.globalLimiter
+	c.limiterMutex.Unlock()
+	return global.wait(cc.ctx, n)
+}
@@ -0,0 +1,181 @@
+package vyxclient
+
+import (
+	"sync"
+	"time"
+)
+
+// ReconnectScheduledCallback is an optional extension of Callback
+// OnReconnectScheduled fires right before the client waits to reconnect,
+// with the delay after backoff and cooldowns and the number of the attempt
+// that follows, so apps can show a countdown. Apps not implementing it just
+// don't get the countdown.
+type ReconnectScheduledCallback interface {
+	OnReconnectScheduled(delayMillis int, attempt int)
+}
+
+// reconnectGate holds the connection loop while reconnection is paused
+type reconnectGate struct {
+	mu      sync.Mutex
+	resumed chan struct{} // non-nil while paused, closed on resume
+	// wake cuts a pending backoff delay short
+	wake chan struct{}
+	// immediate skips the next backoff delay altogether
+	immediate bool
+}
+
+// Pause stops all traffic but keeps the client reusable
+// Relayed connections are canceled, the QUIC connection is closed gracefully
+// and no reconnection is attempted until Resume. Unlike Stop, the client can
+// be resumed with its configuration and state intact. Pausing twice is a no-op.
+func (c *Client) Pause() {
+	if !c.paused.CompareAndSwap(false, true) {
+		return
+	}
+	c.log("Client paused")
+	c.PauseReconnection()
+	c.requestReconnect("Paused")
+	c.closeAllConnections()
+}
+
+// Resume undoes Pause and reconnects immediately
+// Does nothing if the client isn't paused.
+func (c *Client) Resume() {
+	if !c.paused.CompareAndSwap(true, false) {
+		return
+	}
+	c.log("Client resumed")
+	if !c.awaitingToken.Load() {
+		c.ResumeReconnection()
+	}
+}
+
+// ForceReconnect drops the current connection and reconnects right away
+// Call it from the app's ConnectivityManager callback when the network
+// changes (WiFi <-> cellular): the old connection is most likely dead, and
+// waiting for it to time out costs seconds. Backoff and pending retry delays
+// are skipped, but the flap cooldown (SetFlapProtection) still applies so a
+// flapping network can't bypass it. Safe to call repeatedly and while
+// disconnected; while reconnection is paused the client still waits for
+// ResumeReconnection.
+func (c *Client) ForceReconnect() {
+	c.retryMutex.Lock()
+	c.consecutiveFailures = 0
+	c.retryMutex.Unlock()
+
+	c.reconnect.mu.Lock()
+	c.reconnect.immediate = true
+	c.wakeReconnect()
+	c.reconnect.mu.Unlock()
+
+	c.requestReconnect("Network changed")
+}
+
+// takeImmediateReconnect reports and clears a pending ForceReconnect
+func (c *Client) takeImmediateReconnect() bool {
+	c.reconnect.mu.Lock()
+	defer c.reconnect.mu.Unlock()
+
+	immediate := c.reconnect.immediate
+	c.reconnect.immediate = false
+	return immediate
+}
+
+// IsPaused returns true between Pause and Resume
+func (c *Client) IsPaused() bool {
+	return c.paused.Load()
+}
+
+// PauseReconnection stops the client from making new connection attempts
+// e.g. while the app is in the background without a foreground service.
+// An established connection is left alone, but once it drops the client
+// waits without touching the network until ResumeReconnection. Unlike Stop,
+// configuration and state are kept.
+func (c *Client) PauseReconnection() {
+	c.reconnect.mu.Lock()
+	defer c.reconnect.mu.Unlock()
+
+	if c.reconnect.resumed == nil {
+		c.reconnect.resumed = make(chan struct{})
+		c.log("Reconnection paused")
+	}
+}
+
+// ResumeReconnection lets the client connect again, immediately if it's waiting
+func (c *Client) ResumeReconnection() {
+	c.reconnect.mu.Lock()
+	defer c.reconnect.mu.Unlock()
+
+	if c.reconnect.resumed != nil {
+		close(c.reconnect.resumed)
+		c.reconnect.resumed = nil
+		c.log("Reconnection resumed")
+	}
+	c.wakeReconnect()
+}
+
+// wakeReconnect ends a pending backoff delay so the next attempt starts now
+// Caller must hold reconnect.mu.
+func (c *Client) wakeReconnect() {
+	if c.reconnect.wake == nil {
+		c.reconnect.wake = make(chan struct{}, 1)
+	}
+	select {
+	case c.reconnect.wake <- struct{}{}:
+	default:
+	}
+}
+
+// reconnectWake returns the channel signaled by wakeReconnect
+// A wake-up sent before the delay started is discarded.
+func (c *Client) reconnectWake() chan struct{} {
+	c.reconnect.mu.Lock()
+	defer c.reconnect.mu.Unlock()
+
+	if c.reconnect.wake == nil {
+		c.reconnect.wake = make(chan struct{}, 1)
+	}
+	select {
+	case <-c.reconnect.wake:
+	default:
+	}
+	return c.reconnect.wake
+}
+
+// IsReconnectionPaused returns true between PauseReconnection and ResumeReconnection
+func (c *Client) IsReconnectionPaused() bool {
+	c.reconnect.mu.Lock()
+	defer c.reconnect.mu.Unlock()
+	return c.reconnect.resumed != nil
+}
+
+// waitWhilePaused blocks while reconnection is paused
+// Returns false if the client was stopped while waiting.
+func (c *Client) waitWhilePaused() bool {
+	c.reconnect.mu.Lock()
+	resumed := c.reconnect.resumed
+	c.reconnect.mu.Unlock()
+
+	if resumed == nil {
+		return true
+	}
+
+	select {
+	case <-resumed:
+		return true
+	case <-c.ctx.Done():
+		return false
+	}
+}
+
+// notifyReconnectScheduled tells the app when the next attempt will start
+func (c *Client) notifyReconnectScheduled(delay time.Duration) {
+	cb, ok := c.callback.(ReconnectScheduledCallback)
+	if !ok {
+		return
+	}
+	c.retryMutex.Lock()
+	attempt := c.consecutiveFailures + 1
+	c.retryMutex.Unlock()
+	cb.OnReconnectScheduled(int(delay.Milliseconds()), attempt)
+}
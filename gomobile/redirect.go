@@ -0,0 +1,65 @@
+package vyxclient
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetRedirectDrainTimeout bounds how long a server redirect waits for
+// outgoing data to be flushed and confirmed before reconnecting
+func (c *Client) SetRedirectDrainTimeout(timeoutMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.RedirectDrainMs = timeoutMillis
+	})
+}
+
+// handleRedirect moves the client to the server named in a "redirect"
+// Pending sends are flushed first and the server confirms it received them
+// (the "redirect_ack" request), so in-flight data isn't lost.
+func (c *Client) handleRedirect(target string) {
+	if target == "" {
+		c.log("Ignoring redirect without a target")
+		return
+	}
+
+	c.log(fmt.Sprintf("Server redirected us to %s, draining", target))
+	deadline := time.Now().Add(time.Duration(c.getConfig().RedirectDrainMs) * time.Millisecond)
+
+	if !c.drainSends(deadline) {
+		c.log("Redirect drain timed out with sends still pending")
+	}
+
+	// The stream is ordered, so the answer means everything before it arrived
+	if remaining := time.Until(deadline); remaining > 0 {
+		if _, err := c.request(&Message{Type: "redirect_ack", Addr: target}, remaining); err != nil {
+			c.log(fmt.Sprintf("Redirect not confirmed: %v", err))
+		}
+	}
+
+	c.serverMutex.Lock()
+	c.serverURL = target
+	c.serverMutex.Unlock()
+
+	c.requestReconnect(fmt.Sprintf("Redirected to %s", target))
+}
+
+// drainSends flushes queued writes and waits for in-progress sends to finish
+// Returns false if sends were still pending at the deadline.
+func (c *Client) drainSends(deadline time.Time) bool {
+	for {
+		c.quicMutex.Lock()
+		writer := c.streamWriter
+		c.quicMutex.Unlock()
+		if writer != nil {
+			writer.flush()
+		}
+
+		if c.sendSched.idle() {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
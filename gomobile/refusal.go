@@ -0,0 +1,75 @@
+package vyxclient
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Reasons for refusing a server "connect" locally
+const (
+	refusedMaxConnections = "max_connections"
+)
+
+// ConnectionRefusedCallback is an optional extension of Callback
+// OnConnectionRefused fires when the client refuses a "connect" because of
+// local policy; reason is one of the refusal reasons shown in GetStats.
+type ConnectionRefusedCallback interface {
+	OnConnectionRefused(id string, addr string, reason string)
+}
+
+// refusalCounters counts refused connections per reason
+type refusalCounters struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+// SetMaxConnections caps the connections relayed by the Go-side dialer
+// Further "connect" requests are refused. 0 means unlimited.
+func (c *Client) SetMaxConnections(max int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.MaxConnections = max
+	})
+}
+
+// checkConnectPolicy returns a refusal reason for a "connect", or "" to allow it
+func (c *Client) checkConnectPolicy(addr string) string {
+	if max := c.getConfig().MaxConnections; max > 0 {
+		c.clientMutex.RLock()
+		count := len(c.clientConns)
+		c.clientMutex.RUnlock()
+		if count >= max {
+			return refusedMaxConnections
+		}
+	}
+	return ""
+}
+
+// refuseConnection rejects a "connect" by replying "close" with the reason
+func (c *Client) refuseConnection(id string, addr string, reason string) {
+	c.refusals.mu.Lock()
+	if c.refusals.counts == nil {
+		c.refusals.counts = make(map[string]int64)
+	}
+	c.refusals.counts[reason]++
+	c.refusals.mu.Unlock()
+
+	c.log(fmt.Sprintf("Refusing connection %s to %s: %s", id, addr, reason))
+	c.sendMessage(&Message{Type: "close", ID: id, Data: reason})
+	c.markClosed(id)
+
+	if cb, ok := c.callback.(ConnectionRefusedCallback); ok {
+		cb.OnConnectionRefused(id, addr, reason)
+	}
+}
+
+// refusalSnapshot copies the per-reason refusal counters
+func (c *Client) refusalSnapshot() map[string]int64 {
+	c.refusals.mu.Lock()
+	defer c.refusals.mu.Unlock()
+
+	snap := make(map[string]int64, len(c.refusals.counts))
+	for reason, n := range c.refusals.counts {
+		snap[reason] = n
+	}
+	return snap
+}
@@ -0,0 +1,58 @@
+package vyxclient
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Relay buffer size limits accepted by SetRelayBufferSize
+const (
+	minRelayBufferBytes = 1024
+	maxRelayBufferBytes = 1 << 20
+)
+
+// relayBuffers recycles read buffers of the relays across connections
+// Buffers not matching the configured size are dropped on return, so the
+// pool converges on the new size after SetRelayBufferSize.
+var relayBuffers sync.Pool
+
+// SetRelayBufferSize sets how many bytes relays read from a target at once
+// Larger buffers suit bulk throughput, smaller ones latency and memory.
+// bytes must be between 1 KiB and 1 MiB; default 32 KiB. Applies to
+// connections and relay pool workers started afterwards.
+// Returns error message or empty string on success
+func (c *Client) SetRelayBufferSize(bytes int) string {
+	if err := validateRelayBuffer(bytes); err != nil {
+		return err.Error()
+	}
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.RelayBufferBytes = bytes
+	})
+	return ""
+}
+
+// validateRelayBuffer checks a relay buffer size
+func validateRelayBuffer(bytes int) error {
+	if bytes < minRelayBufferBytes || bytes > maxRelayBufferBytes {
+		return fmt.Errorf("relayBufferBytes must be between %d and %d", minRelayBufferBytes, maxRelayBufferBytes)
+	}
+	return nil
+}
+
+// getRelayBuffer returns a buffer of the configured size, reused if possible
+// Return it with putRelayBuffer once nothing refers to its contents.
+func (c *Client) getRelayBuffer() *[]byte {
+	size := c.getConfig().RelayBufferBytes
+	if buf, ok := relayBuffers.Get().(*[]byte); ok && len(*buf) == size {
+		return buf
+	}
+	buf := make([]byte, size)
+	return &buf
+}
+
+// putRelayBuffer makes a buffer from getRelayBuffer available for reuse
+func (c *Client) putRelayBuffer(buf *[]byte) {
+	if len(*buf) == c.getConfig().RelayBufferBytes {
+		relayBuffers.Put(buf)
+	}
+}
@@ -0,0 +1,31 @@
+package vyxclient
+
+import "testing"
+
+// relayBufferSink keeps the unpooled buffers from being optimized away
+var relayBufferSink []byte
+
+// BenchmarkRelayBuffer compares taking a relay buffer per connection from
+// the pool against allocating a new one
+func BenchmarkRelayBuffer(b *testing.B) {
+	c := NewClient("127.0.0.1:1", "test-token", "test", "{}", nil)
+	defer c.Stop()
+	size := c.getConfig().RelayBufferBytes
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := c.getRelayBuffer()
+			(*buf)[0] = byte(i)
+			c.putRelayBuffer(buf)
+		}
+	})
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := make([]byte, size)
+			buf[0] = byte(i)
+			relayBufferSink = buf
+		}
+	})
+}
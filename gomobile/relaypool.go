@@ -0,0 +1,224 @@
+package vyxclient
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Relay modes accepted by SetRelayMode
+const (
+	RelayModeGoroutines = "goroutines"
+	RelayModePool       = "pool"
+)
+
+// Pool relay tuning
+const (
+	// poolReadWait is how long a worker waits for data on one connection
+	poolReadWait = time.Millisecond
+	// poolIdleSleep is how long workers pause after a pass with nothing to do
+	poolIdleSleep = 5 * time.Millisecond
+	// poolMaxWrites bounds the queued writes serviced per turn
+	poolMaxWrites = 16
+)
+
+// pooledConn is a connection serviced by the relay pool
+type pooledConn struct {
+	cc *Connection
+	id string
+}
+
+// relayPool services many connections with a fixed set of workers
+// Connections wait in a round-robin queue; a worker takes one, writes what's
+// queued for it, polls it for data with a short read deadline and puts it
+// back. This trades some latency and idle CPU for far fewer goroutines.
+type relayPool struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []*pooledConn
+	members int
+	started bool
+}
+
+// SetRelayMode selects how Go-side connections are relayed
+// "goroutines" (default) uses two goroutines per connection, which has the
+// lowest latency. "pool" services all connections with workers goroutines
+// (0 picks twice the CPU count), for thousands of mostly idle connections.
+// Applies to connections registered afterwards; the worker count is fixed
+// once the pool starts.
+// Returns error message or empty string on success
+func (c *Client) SetRelayMode(mode string, workers int) string {
+	if mode != RelayModeGoroutines && mode != RelayModePool {
+		return fmt.Sprintf("unknown relay mode %q", mode)
+	}
+	if workers < 0 {
+		return "workers must not be negative"
+	}
+
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.RelayMode = mode
+		cfg.RelayWorkers = workers
+	})
+	return ""
+}
+
+// addToRelayPool hands a registered connection to the pool workers
+func (c *Client) addToRelayPool(cc *Connection, id string) {
+	p := &c.relayPool
+
+	p.mu.Lock()
+	if !p.started {
+		p.started = true
+		p.cond = sync.NewCond(&p.mu)
+
+		workers := c.getConfig().RelayWorkers
+		if workers <= 0 {
+			workers = 2 * runtime.NumCPU()
+		}
+		for i := 0; i < workers; i++ {
+			go c.relayPoolWorker()
+		}
+
+		// Wake idle workers on Stop
+		go func() {
+			<-c.ctx.Done()
+			p.mu.Lock()
+			p.cond.Broadcast()
+			p.mu.Unlock()
+		}()
+	}
+	cc.relays.Add(1)
+	p.queue = append(p.queue, &pooledConn{cc: cc, id: id})
+	p.members++
+	p.cond.Signal()
+	p.mu.Unlock()
+}
+
+// relayPoolWorker services queued connections until the client stops
+func (c *Client) relayPoolWorker() {
+	p := &c.relayPool
+	pooled := c.getRelayBuffer()
+	defer c.putRelayBuffer(pooled)
+	buffer := *pooled
+	idleStreak := 0
+
+	for {
+		p.mu.Lock()
+		for len(p.queue) == 0 && c.ctx.Err() == nil {
+			p.cond.Wait()
+		}
+		if c.ctx.Err() != nil {
+			p.mu.Unlock()
+			return
+		}
+		pc := p.queue[0]
+		p.queue = p.queue[1:]
+		queued := len(p.queue)
+		p.mu.Unlock()
+
+		progressed, open := c.servicePooled(pc, buffer)
+		if !open {
+			p.mu.Lock()
+			p.members--
+			p.mu.Unlock()
+			pc.cc.relays.Done()
+			continue
+		}
+
+		p.mu.Lock()
+		p.queue = append(p.queue, pc)
+		p.mu.Unlock()
+
+		// Back off once a full pass over the queue found nothing to do
+		if progressed {
+			idleStreak = 0
+		} else if idleStreak++; idleStreak > queued {
+			idleStreak = 0
+			time.Sleep(poolIdleSleep)
+		}
+	}
+}
+
+// servicePooled gives one connection a turn in both directions
+// Returns whether any data moved and whether the connection is still open.
+func (c *Client) servicePooled(pc *pooledConn, buffer []byte) (bool, bool) {
+	cc := pc.cc
+	if cc.ctx.Err() != nil {
+		return false, false
+	}
+	progressed := false
+
+	// Server -> target
+	for i := 0; i < poolMaxWrites; i++ {
+		select {
+		case data, ok := <-cc.dataChan:
+			if !ok {
+				return progressed, false
+			}
+			if c.throttle(cc, len(data)) != nil {
+				return progressed, false
+			}
+			start := c.histogramClock()
+			if err := writeFull(cc.conn, data); err != nil {
+				c.closeConnection(pc.id, true)
+				return progressed, false
+			}
+			c.recordDownlinkWrite(start, len(data))
+			c.countIn(cc, len(data))
+			cc.touch()
+			progressed = true
+			continue
+		default:
+		}
+		break
+	}
+
+	// Target -> server
+	cc.conn.SetReadDeadline(time.Now().Add(poolReadWait))
+	n, err := cc.conn.Read(buffer)
+	if n > 0 {
+		progressed = true
+		cc.touch()
+		if c.throttle(cc, n) != nil {
+			return progressed, false
+		}
+		start := c.histogramClock()
+		sendErr := c.sendData(c.dataMessage(pc.id, buffer[:n]))
+		if sendErr != nil {
+			c.log(fmt.Sprintf("Failed to relay data for %s: %v", pc.id, sendErr))
+			c.closeConnection(pc.id, true)
+			return progressed, false
+		}
+		c.recordUplinkSend(start, n)
+		c.countOut(cc, n)
+	}
+	if err != nil && !isReadTimeout(err) {
+		c.closeConnection(pc.id, true)
+		return progressed, false
+	}
+	if !progressed && cc.idleExpired() {
+		c.closeIdle(cc, pc.id)
+		return progressed, false
+	}
+	return progressed, true
+}
+
+// isReadTimeout reports whether err is an expired read deadline
+func isReadTimeout(err error) bool {
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// relayPoolSize returns the number of connections in the pool
+func (c *Client) relayPoolSize() int {
+	c.relayPool.mu.Lock()
+	defer c.relayPool.mu.Unlock()
+	return c.relayPool.members
+}
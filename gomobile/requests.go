@@ -0,0 +1,140 @@
+package vyxclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// errTooManyPending is returned when the pending request limit is reached
+var errTooManyPending = errors.New("too many pending requests")
+
+// pendingRequests correlates outgoing requests with their responses by Ref
+type pendingRequests struct {
+	mu      sync.Mutex
+	waiters map[string]chan *Message
+}
+
+// SetMaxPendingRequests caps the number of outstanding correlated requests
+// (SendMessageAndWaitResponse, PingSync). Further calls fail immediately.
+func (c *Client) SetMaxPendingRequests(max int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.MaxPendingRequests = max
+	})
+}
+
+// SendMessageAndWaitResponse sends a message and waits for the server's reply
+// The server must echo the message's "ref" field on its response.
+// Returns the response as JSON.
+func (c *Client) SendMessageAndWaitResponse(messageType string, id string, addr string, data string, timeoutMillis int) (string, error) {
+	msg := &Message{
+		Type: messageType,
+		ID:   id,
+		Addr: addr,
+		Data: data,
+	}
+
+	response, err := c.request(msg, time.Duration(timeoutMillis)*time.Millisecond)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal response: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// PingSync sends a ping and waits for the matching pong
+// Returns the round-trip time in milliseconds, or -1 on failure.
+func (c *Client) PingSync(timeoutMillis int) int {
+	start := time.Now()
+	sent := nowMillis()
+	response, err := c.request(&Message{Type: "ping"}, time.Duration(timeoutMillis)*time.Millisecond)
+	if err != nil {
+		c.log(fmt.Sprintf("Ping failed: %v", err))
+		return -1
+	}
+	rtt := time.Since(start)
+	c.recordTimeSample(sent, nowMillis(), response.TS)
+	c.recordRTT(rtt)
+	return int(rtt.Milliseconds())
+}
+
+// request sends msg with a fresh Ref and waits for the response carrying it
+func (c *Client) request(msg *Message, timeout time.Duration) (*Message, error) {
+	ref, err := randomHex(8)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate request ref: %w", err)
+	}
+	msg.Ref = ref
+
+	responseChan, err := c.addPending(ref)
+	if err != nil {
+		return nil, err
+	}
+	// Reclaim the entry whether we got a response or not
+	defer c.removePending(ref)
+
+	if err := c.sendMessage(msg); err != nil {
+		return nil, err
+	}
+
+	select {
+	case response := <-responseChan:
+		return response, nil
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("request timed out after %v", timeout)
+	case <-c.ctx.Done():
+		return nil, errors.New("client stopped")
+	}
+}
+
+// addPending registers a waiter for ref, enforcing the pending limit
+func (c *Client) addPending(ref string) (chan *Message, error) {
+	max := c.getConfig().MaxPendingRequests
+
+	c.pending.mu.Lock()
+	defer c.pending.mu.Unlock()
+
+	if c.pending.waiters == nil {
+		c.pending.waiters = make(map[string]chan *Message)
+	}
+	if max > 0 && len(c.pending.waiters) >= max {
+		return nil, errTooManyPending
+	}
+
+	ch := make(chan *Message, 1)
+	c.pending.waiters[ref] = ch
+	return ch, nil
+}
+
+// removePending drops the waiter for ref
+func (c *Client) removePending(ref string) {
+	c.pending.mu.Lock()
+	delete(c.pending.waiters, ref)
+	c.pending.mu.Unlock()
+}
+
+// resolvePending hands msg to the waiter for its Ref
+// Returns false if nobody is waiting, so the message is handled normally.
+func (c *Client) resolvePending(msg *Message) bool {
+	if msg.Ref == "" {
+		return false
+	}
+
+	c.pending.mu.Lock()
+	ch, ok := c.pending.waiters[msg.Ref]
+	if ok {
+		delete(c.pending.waiters, msg.Ref)
+	}
+	c.pending.mu.Unlock()
+
+	if ok {
+		ch <- msg
+	}
+	return ok
+}
@@ -0,0 +1,226 @@
+package vyxclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Resolver modes accepted by SetResolver
+const (
+	ResolverSystem = "system"
+	ResolverDoH    = "doh"
+	ResolverDoT    = "dot"
+)
+
+// resolveTimeout bounds a single server address resolution
+const resolveTimeout = 10 * time.Second
+
+// SetResolver selects how the server hostname is resolved before dialing
+// mode is "system" (default), "doh" with server a DNS-over-HTTPS URL such as
+// "https://1.1.1.1/dns-query", or "dot" with server a DNS-over-TLS
+// "host:port" such as "1.1.1.1:853". With fallback false a failing resolver
+// fails the connection attempt; with fallback true the system resolver is
+// tried instead, trading tamper resistance for availability. The name is
+// resolved again on every reconnect.
+// Returns error message or empty string on success
+func (c *Client) SetResolver(mode string, server string, fallback bool) string {
+	cfg := c.getConfig()
+	cfg.ResolverMode = mode
+	cfg.ResolverServer = server
+	cfg.ResolverFallback = fallback
+	if err := validateResolver(cfg); err != nil {
+		return err.Error()
+	}
+
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.ResolverMode = mode
+		cfg.ResolverServer = server
+		cfg.ResolverFallback = fallback
+	})
+	return ""
+}
+
+// validateResolver checks the resolver settings
+func validateResolver(cfg clientConfig) error {
+	switch cfg.ResolverMode {
+	case ResolverSystem:
+		return nil
+	case ResolverDoH, ResolverDoT:
+		if cfg.ResolverServer == "" {
+			return fmt.Errorf("resolver %s requires a server", cfg.ResolverMode)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown resolver mode %q", cfg.ResolverMode)
+}
+
+// resolveServerAddr resolves a "host:port" server address for dialing
+func (c *Client) resolveServerAddr(serverAddr string) (*net.UDPAddr, error) {
+	cfg := c.getConfig()
+	if cfg.ResolverMode == ResolverSystem || cfg.ResolverMode == "" {
+		return net.ResolveUDPAddr("udp", serverAddr)
+	}
+
+	host, portStr, err := net.SplitHostPort(serverAddr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port in %s", serverAddr)
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return &net.UDPAddr{IP: ip, Port: port}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, resolveTimeout)
+	defer cancel()
+
+	var ips []net.IP
+	if cfg.ResolverMode == ResolverDoH {
+		ips, err = resolveDoH(ctx, cfg.ResolverServer, host)
+	} else {
+		ips, err = resolveDoT(ctx, cfg.ResolverServer, host)
+	}
+	if err == nil && len(ips) == 0 {
+		err = fmt.Errorf("no addresses for %s", host)
+	}
+	if err != nil {
+		if cfg.ResolverFallback {
+			c.log(fmt.Sprintf("%s resolver failed (%v), falling back to system resolver", cfg.ResolverMode, err))
+			return net.ResolveUDPAddr("udp", serverAddr)
+		}
+		return nil, fmt.Errorf("%s resolver failed: %w", cfg.ResolverMode, err)
+	}
+	return &net.UDPAddr{IP: ips[0], Port: port}, nil
+}
+
+// resolveDoT looks up host over DNS-over-TLS
+func resolveDoT(ctx context.Context, server string, host string) ([]net.IP, error) {
+	serverName, _, err := net.SplitHostPort(server)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DoT server %s: %w", server, err)
+	}
+
+	resolver := &net.Resolver{
+		PreferGo: true,
+		// The Go resolver speaks DNS-over-TCP framing on non-packet conns
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			dialer := &tls.Dialer{Config: &tls.Config{ServerName: serverName, MinVersion: tls.VersionTLS12}}
+			return dialer.DialContext(ctx, "tcp", server)
+		},
+	}
+
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, len(addrs))
+	for i, a := range addrs {
+		ips[i] = a.IP
+	}
+	return ips, nil
+}
+
+// resolveDoH looks up host over DNS-over-HTTPS (RFC 8484), A records first
+func resolveDoH(ctx context.Context, url string, host string) ([]net.IP, error) {
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+		},
+	}
+
+	var ips []net.IP
+	var lastErr error
+	for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		found, err := queryDoH(ctx, client, url, host, qtype)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		ips = append(ips, found...)
+	}
+	if len(ips) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return ips, nil
+}
+
+// queryDoH sends one DNS question to a DoH server
+func queryDoH(ctx context.Context, client *http.Client, url string, host string, qtype dnsmessage.Type) ([]net.IP, error) {
+	name, err := dnsmessage.NewName(dnsFQDN(host))
+	if err != nil {
+		return nil, err
+	}
+
+	// ID 0 is recommended for DoH to keep responses cacheable
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(packed))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DoH server returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+
+	var answer dnsmessage.Message
+	if err := answer.Unpack(body); err != nil {
+		return nil, fmt.Errorf("invalid DoH response: %w", err)
+	}
+	if answer.RCode != dnsmessage.RCodeSuccess {
+		return nil, fmt.Errorf("DoH lookup of %s failed: %v", host, answer.RCode)
+	}
+
+	var ips []net.IP
+	for _, rr := range answer.Answers {
+		switch body := rr.Body.(type) {
+		case *dnsmessage.AResource:
+			ips = append(ips, net.IP(body.A[:]))
+		case *dnsmessage.AAAAResource:
+			ips = append(ips, net.IP(body.AAAA[:]))
+		}
+	}
+	if len(ips) == 0 {
+		return nil, errors.New("no addresses in DoH response")
+	}
+	return ips, nil
+}
+
+// dnsFQDN returns host as a fully qualified domain name
+func dnsFQDN(host string) string {
+	if len(host) > 0 && host[len(host)-1] == '.' {
+		return host
+	}
+	return host + "."
+}
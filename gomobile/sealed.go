@@ -0,0 +1,145 @@
+package vyxclient
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Parameters for passphrase-sealed exports
+const (
+	sealedFormat     = "vyx-sealed"
+	sealedVersion    = 1
+	sealedKDF        = "pbkdf2-sha256"
+	sealedIterations = 600000
+	sealedSaltSize   = 16
+)
+
+var (
+	errWrongPassphrase = errors.New("decryption failed: wrong passphrase or corrupted data")
+	errCorruptedBlob   = errors.New("corrupted blob: not a sealed export")
+)
+
+// sealedBlob is the envelope around AES-GCM encrypted exports
+type sealedBlob struct {
+	Format     string `json:"format"`
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// ExportConfigEncrypted returns ExportConfig output sealed with passphrase
+// The blob is AES-256-GCM encrypted with a key derived from the passphrase
+// (PBKDF2-SHA256), so it's safe to keep in app backups or cloud sync.
+// Restore it with NewClientFromEncryptedConfig.
+func (c *Client) ExportConfigEncrypted(includeToken bool, passphrase string) (string, error) {
+	plaintext, err := json.Marshal(c.exportConfig(includeToken))
+	if err != nil {
+		return "", err
+	}
+	return sealBlob(plaintext, passphrase)
+}
+
+// NewClientFromEncryptedConfig creates a client from ExportConfigEncrypted output
+// Returns an error if the passphrase is wrong or the blob is corrupted.
+func NewClientFromEncryptedConfig(blob string, passphrase string, callback Callback) (*Client, error) {
+	plaintext, err := openBlob(blob, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientFromConfig(string(plaintext), callback)
+}
+
+// sealBlob encrypts plaintext with a key derived from passphrase
+func sealBlob(plaintext []byte, passphrase string) (string, error) {
+	if passphrase == "" {
+		return "", errors.New("passphrase is required")
+	}
+
+	salt := make([]byte, sealedSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	aead, err := sealedAEAD(passphrase, salt, sealedIterations)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	blob := sealedBlob{
+		Format:     sealedFormat,
+		Version:    sealedVersion,
+		KDF:        sealedKDF,
+		Iterations: sealedIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+	}
+	// Bind the envelope header to the ciphertext
+	blob.Ciphertext = aead.Seal(nil, nonce, plaintext, blob.additionalData())
+
+	data, err := json.Marshal(blob)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// openBlob decrypts a blob produced by sealBlob
+func openBlob(data string, passphrase string) ([]byte, error) {
+	var blob sealedBlob
+	if err := json.Unmarshal([]byte(data), &blob); err != nil || blob.Format != sealedFormat {
+		return nil, errCorruptedBlob
+	}
+	if blob.Version > sealedVersion {
+		return nil, fmt.Errorf("unsupported sealed export version %d (max %d)", blob.Version, sealedVersion)
+	}
+	if blob.KDF != sealedKDF {
+		return nil, fmt.Errorf("unsupported key derivation %q", blob.KDF)
+	}
+	if blob.Iterations <= 0 || len(blob.Salt) == 0 {
+		return nil, errCorruptedBlob
+	}
+
+	aead, err := sealedAEAD(passphrase, blob.Salt, blob.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(blob.Nonce) != aead.NonceSize() {
+		return nil, errCorruptedBlob
+	}
+
+	plaintext, err := aead.Open(nil, blob.Nonce, blob.Ciphertext, blob.additionalData())
+	if err != nil {
+		return nil, errWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// sealedAEAD derives the AES-256-GCM cipher for passphrase and salt
+func sealedAEAD(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData returns the authenticated envelope header
+func (b sealedBlob) additionalData() []byte {
+	return []byte(fmt.Sprintf("%s/%d/%s/%d", b.Format, b.Version, b.KDF, b.Iterations))
+}
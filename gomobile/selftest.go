@@ -0,0 +1,133 @@
+package vyxclient
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// selfTestStage is one step of a SelfTest report
+type selfTestStage struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Ms     int64  `json:"ms"`
+	Error  string `json:"error,omitempty"`
+}
+
+// selfTestReport is the JSON shape returned by SelfTest
+type selfTestReport struct {
+	Server  string          `json:"server"`
+	Passed  bool            `json:"passed"`
+	TotalMs int64           `json:"totalMs"`
+	Stages  []selfTestStage `json:"stages"`
+	// Negotiated details, set once the handshake passed
+	TLSVersion string `json:"tlsVersion,omitempty"`
+	ALPN       string `json:"alpn,omitempty"`
+	// ProtocolVersion is the version agreed during auth
+	ProtocolVersion int `json:"protocolVersion,omitempty"`
+}
+
+// SelfTest checks that a server can be reached and authenticated against
+// For QA automation: it resolves the first server in serverURL, dials it,
+// completes the QUIC/TLS handshake and authenticates with token, then tears
+// everything down. No Callback is needed and nothing reconnects. Returns
+// JSON {"server", "passed", "totalMs", "stages": [{"name", "passed", "ms",
+// "error"}, ...], ...} with the stages dns, dial, handshake and auth; stages
+// after the first failure are left out.
+func SelfTest(serverURL string, token string, timeoutMillis int) string {
+	c := NewClient(serverURL, token, "selftest", "", nil)
+	defer c.Stop()
+
+	report := c.selfTest(time.Duration(timeoutMillis) * time.Millisecond)
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// selfTest runs the SelfTest stages, all within timeout
+func (c *Client) selfTest(timeout time.Duration) selfTestReport {
+	start := time.Now()
+	report := selfTestReport{Server: c.currentServer()}
+
+	ctx := c.ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(c.ctx, timeout)
+		defer cancel()
+	}
+
+	// stage runs one step and records it, returning false if it failed
+	stage := func(name string, fn func() error) bool {
+		began := time.Now()
+		err := fn()
+		if err != nil && ctx.Err() != nil {
+			err = fmt.Errorf("timed out: %w", err)
+		}
+		result := selfTestStage{Name: name, Passed: err == nil, Ms: time.Since(began).Milliseconds()}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		report.Stages = append(report.Stages, result)
+		return err == nil
+	}
+
+	var serverAddr string
+	var udpAddr *net.UDPAddr
+	var transport *quic.Transport
+	var conn *quic.Conn
+	defer func() {
+		if conn != nil {
+			conn.CloseWithError(0, "self test done")
+		}
+	}()
+
+	report.Passed = stage("dns", func() error {
+		var err error
+		serverAddr, err = normalizeServerAddr(report.Server)
+		if err != nil {
+			return err
+		}
+		udpAddr, err = c.resolveServerAddr(serverAddr)
+		return err
+	}) && stage("dial", func() error {
+		var err error
+		transport, err = c.ensureTransport()
+		return err
+	}) && stage("handshake", func() error {
+		var err error
+		conn, err = transport.Dial(ctx, udpAddr, c.buildTLSConfig(serverAddr), c.buildQUICConfig())
+		if err != nil {
+			return err
+		}
+		state := conn.ConnectionState().TLS
+		report.TLSVersion = tls.VersionName(state.Version)
+		report.ALPN = state.NegotiatedProtocol
+		return nil
+	}) && stage("auth", func() error {
+		// Unblock the auth read if the deadline passes
+		stop := context.AfterFunc(ctx, func() {
+			conn.CloseWithError(1, "self test timed out")
+		})
+		defer stop()
+
+		stream, err := c.openStream(conn)
+		if err != nil {
+			return err
+		}
+		if err := c.authenticate(stream, c.newStreamDecoder(stream)); err != nil {
+			return err
+		}
+		report.ProtocolVersion = c.GetProtocolVersion()
+		return nil
+	})
+
+	report.TotalMs = time.Since(start).Milliseconds()
+	return report
+}
@@ -0,0 +1,76 @@
+package vyxclient
+
+import "errors"
+
+// Send result codes of SendResult.Code
+const (
+	// SendOK means the message was written
+	SendOK = 0
+	// SendErrNoStream means there's no control stream, e.g. while reconnecting
+	SendErrNoStream = 1
+	// SendErrWrite means writing to the stream failed
+	SendErrWrite = 2
+	// SendErrMarshal means the message couldn't be encoded; a bug, don't retry
+	SendErrMarshal = 3
+	// SendErrUnsupported means the server's protocol version lacks the message type
+	SendErrUnsupported = 4
+	// SendErrInvalidData means the data isn't valid base64 for an encrypted connection
+	SendErrInvalidData = 5
+	// SendErrTimeout means the server stopped reading; the client reconnects
+	SendErrTimeout = 6
+)
+
+// SendResult is the outcome of SendMessageResult
+type SendResult struct {
+	// Code is one of the Send codes, SendOK on success
+	Code int
+	// Message describes the error, empty on success
+	Message string
+	// Retryable is true if sending the same message again may succeed
+	Retryable bool
+}
+
+// sendError tags a sendMessage failure with its SendResult code
+type sendError struct {
+	code int
+	err  error
+}
+
+func (e *sendError) Error() string {
+	return e.err.Error()
+}
+
+func (e *sendError) Unwrap() error {
+	return e.err
+}
+
+// SendMessageResult sends a message to the server like SendMessage
+// The result says why a send failed and whether retrying may help, so apps
+// can retry transient write errors and surface encoding bugs.
+func (c *Client) SendMessageResult(messageType string, id string, addr string, data string) *SendResult {
+	msg := &Message{
+		Type: messageType,
+		ID:   id,
+		Addr: addr,
+		Data: data,
+	}
+
+	if err := c.sendMessage(msg); err != nil {
+		return c.sendResult(err)
+	}
+	if messageType == "close" {
+		c.connectionEnded(id)
+		c.markClosed(id)
+	}
+	return &SendResult{Code: SendOK}
+}
+
+// sendResult describes a sendMessage error
+func (c *Client) sendResult(err error) *SendResult {
+	code := SendErrWrite
+	var tagged *sendError
+	if errors.As(err, &tagged) {
+		code = tagged.code
+	}
+	return &SendResult{Code: code, Message: err.Error(), Retryable: c.isTransientSendError(err)}
+}
@@ -0,0 +1,57 @@
+package vyxclient
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// Bounded retry of data sends that fail transiently
+const (
+	dataSendRetries    = 2
+	dataSendRetryDelay = 50 * time.Millisecond
+)
+
+// errNoStream is returned by sendMessage while there's no control stream,
+// e.g. while the control stream is being reopened
+var errNoStream = errors.New("no active QUIC stream")
+
+// sendData sends a data message, retrying briefly on transient errors
+// Connection-level failures (the QUIC connection or stream was closed) are
+// returned immediately.
+func (c *Client) sendData(msg *Message) error {
+	err := c.sendMessage(msg)
+	for attempt := 0; attempt < dataSendRetries && err != nil && c.isTransientSendError(err); attempt++ {
+		select {
+		case <-time.After(dataSendRetryDelay):
+		case <-c.ctx.Done():
+			return err
+		}
+		err = c.sendMessage(msg)
+	}
+	return err
+}
+
+// isTransientSendError reports whether a send may succeed if retried
+func (c *Client) isTransientSendError(err error) bool {
+	// The control stream is being replaced on a live connection
+	if errors.Is(err, errNoStream) {
+		return c.IsConnected()
+	}
+	// The client reconnects after a write timeout
+	if errors.Is(err, errWriteTimeout) {
+		return true
+	}
+
+	// quic-go's idle timeout is a net.Error timeout too, but it's fatal
+	var idleErr *quic.IdleTimeoutError
+	if errors.As(err, &idleErr) {
+		return false
+	}
+
+	// A write deadline expired without the stream failing
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
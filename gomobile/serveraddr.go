@@ -0,0 +1,93 @@
+package vyxclient
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// defaultServerPort is used for server addresses without a port
+const defaultServerPort = "8443"
+
+// normalizeServerAddr turns a server address into "host:port" for dialing
+// Accepted forms are "host", "host:port", bare IPv6 ("2001:db8::1"),
+// bracketed IPv6 ("[2001:db8::1]") and bracketed IPv6 with a port
+// ("[2001:db8::1]:8443"). A bare IPv6 literal is never split into a host
+// and port, so IPv6 with a port must be bracketed.
+func normalizeServerAddr(addr string) (string, error) {
+	addr = strings.TrimSpace(addr)
+	if addr == "" {
+		return "", fmt.Errorf("empty server address")
+	}
+
+	if host, port, err := net.SplitHostPort(addr); err == nil {
+		if host == "" || port == "" {
+			return "", fmt.Errorf("invalid server address %q", addr)
+		}
+		return addr, nil
+	}
+
+	if strings.HasPrefix(addr, "[") && strings.HasSuffix(addr, "]") {
+		addr = addr[1 : len(addr)-1]
+		if net.ParseIP(addr) == nil {
+			return "", fmt.Errorf("invalid IPv6 address %q", addr)
+		}
+	} else if strings.Contains(addr, ":") && net.ParseIP(addr) == nil {
+		return "", fmt.Errorf("invalid server address %q", addr)
+	}
+	return net.JoinHostPort(addr, defaultServerPort), nil
+}
+
+// serverHost returns the host part of a normalized server address
+func serverHost(serverAddr string) string {
+	host, _, err := net.SplitHostPort(serverAddr)
+	if err != nil {
+		return serverAddr
+	}
+	return host
+}
+
+// isDevelopmentHost reports whether host is a local development server
+func isDevelopmentHost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// GetServerURL returns the server the client connects to next
+// This is the server of the current connection while connected, unless it
+// was changed since.
+func (c *Client) GetServerURL() string {
+	return c.currentServer()
+}
+
+// UpdateServerURL changes the primary server, e.g. to fail over to another region
+// serverURL may also be a comma-separated list as in SetServerList. The
+// fallback servers are kept behind the new ones. The current connection
+// is kept until it next reconnects unless reconnectNow is set, in which case
+// the client moves to the new server right away (also cutting short a
+// pending retry delay).
+// Returns error message or empty string on success
+func (c *Client) UpdateServerURL(serverURL string, reconnectNow bool) string {
+	if err := validateServerList(serverURL); err != nil {
+		return err.Error()
+	}
+
+	c.serverMutex.Lock()
+	previous := c.serverURL
+	c.setServersLocked(serverURL)
+	c.serverMutex.Unlock()
+
+	c.retryMutex.Lock()
+	c.consecutiveFailures = 0
+	c.retryMutex.Unlock()
+
+	c.log(fmt.Sprintf("Server changed: %s -> %s", previous, serverURL))
+	if reconnectNow {
+		if c.IsConnected() {
+			c.requestReconnect(fmt.Sprintf("Server changed to %s", serverURL))
+		}
+		c.reconnect.mu.Lock()
+		c.wakeReconnect()
+		c.reconnect.mu.Unlock()
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package vyxclient
+
+import "testing"
+
+func TestNormalizeServerAddr(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "proxy.example.com", want: "proxy.example.com:8443"},
+		{in: "proxy.example.com:443", want: "proxy.example.com:443"},
+		{in: "  proxy.example.com:443 ", want: "proxy.example.com:443"},
+		{in: "192.0.2.1", want: "192.0.2.1:8443"},
+		{in: "192.0.2.1:443", want: "192.0.2.1:443"},
+		{in: "2001:db8::1", want: "[2001:db8::1]:8443"},
+		{in: "::1", want: "[::1]:8443"},
+		{in: "[2001:db8::1]", want: "[2001:db8::1]:8443"},
+		{in: "[2001:db8::1]:443", want: "[2001:db8::1]:443"},
+		{in: "", wantErr: true},
+		{in: "proxy.example.com:", wantErr: true},
+		{in: ":443", wantErr: true},
+		{in: "[proxy.example.com]", wantErr: true},
+		{in: "2001:db8::1:443:x", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeServerAddr(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("normalizeServerAddr(%q) = %q, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("normalizeServerAddr(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
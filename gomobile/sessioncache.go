@@ -0,0 +1,89 @@
+package vyxclient
+
+import (
+	"crypto/tls"
+	"sync"
+)
+
+// sessionCacheCapacity bounds the number of cached TLS session tickets
+const sessionCacheCapacity = 32
+
+// sessionCache is a tls.ClientSessionCache that can be inspected and cleared
+// It lives as long as the Client, so tickets survive reconnects and let
+// the next handshake resume the previous TLS session.
+type sessionCache struct {
+	mu      sync.Mutex
+	entries map[string]*tls.ClientSessionState
+	order   []string // oldest first, for eviction
+}
+
+// Get implements tls.ClientSessionCache
+func (s *sessionCache) Get(sessionKey string) (*tls.ClientSessionState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cs, ok := s.entries[sessionKey]
+	return cs, ok
+}
+
+// Put implements tls.ClientSessionCache, a nil state removes the entry
+func (s *sessionCache) Put(sessionKey string, cs *tls.ClientSessionState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entries == nil {
+		s.entries = make(map[string]*tls.ClientSessionState)
+	}
+
+	if _, exists := s.entries[sessionKey]; exists {
+		s.removeLocked(sessionKey)
+	}
+	if cs == nil {
+		return
+	}
+
+	for len(s.order) >= sessionCacheCapacity {
+		s.removeLocked(s.order[0])
+	}
+	s.entries[sessionKey] = cs
+	s.order = append(s.order, sessionKey)
+}
+
+// removeLocked deletes an entry, s.mu must be held
+func (s *sessionCache) removeLocked(sessionKey string) {
+	delete(s.entries, sessionKey)
+	for i, k := range s.order {
+		if k == sessionKey {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// size returns the number of cached tickets
+func (s *sessionCache) size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// clear drops all cached tickets
+func (s *sessionCache) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = nil
+	s.order = nil
+}
+
+// GetSessionCacheSize returns how many TLS session tickets are cached
+func (c *Client) GetSessionCacheSize() int {
+	return c.sessions.size()
+}
+
+// ClearSessionCache drops all cached session tickets
+// The next connection does a full handshake, e.g. after the server rotated
+// its ticket keys and resumption keeps failing.
+func (c *Client) ClearSessionCache() {
+	c.sessions.clear()
+	c.log("TLS session cache cleared")
+}
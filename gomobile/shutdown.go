@@ -0,0 +1,47 @@
+package vyxclient
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// refusedShuttingDown refuses "connect" requests during StopGraceful
+const refusedShuttingDown = "shutting_down"
+
+// errShuttingDown refuses new connections from the app during StopGraceful
+var errShuttingDown = errors.New("client is shutting down")
+
+// reasonClientStopped is reported for connections closed by StopGraceful
+const reasonClientStopped = "client_stopped"
+
+// StopGraceful drains pending data and closes every connection before stopping
+// Reconnection stops and new connections are refused, whether requested by
+// the server, OpenConnection, AcquireConnection or the warm pool. Queued data
+// is flushed towards the targets and the server for up to timeoutMillis,
+// then each active connection is closed with "close" and the QUIC
+// connection with code 0.
+// Returns "drained" if everything was flushed, or "timeout" if data may have
+// been lost.
+func (c *Client) StopGraceful(timeoutMillis int) string {
+	c.stopping.Store(true)
+	defer c.stopping.Store(false)
+	c.PauseReconnection()
+
+	result := "drained"
+	if c.IsConnected() {
+		start := time.Now()
+		if c.FlushAll(timeoutMillis) < 0 {
+			result = "timeout"
+		}
+		c.log(fmt.Sprintf("Graceful stop: %s after %v", result, time.Since(start).Round(time.Millisecond)))
+
+		for id := range c.openConnections() {
+			c.closeWithReason(id, reasonClientStopped)
+		}
+		c.flushControlStream()
+	}
+
+	c.Stop()
+	return result
+}
@@ -0,0 +1,216 @@
+package vyxclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// clientStats holds cumulative counters, updated lock-free on hot paths
+type clientStats struct {
+	bytesSent        atomic.Int64
+	bytesReceived    atomic.Int64
+	messagesSent     atomic.Int64
+	messagesReceived atomic.Int64
+	connects         atomic.Int64
+	connectFailures  atomic.Int64
+	// Connections requested (server connect or OpenConnection) and closed
+	connectionsOpened atomic.Int64
+	connectionsClosed atomic.Int64
+	streamWaiters     atomic.Int64
+	// lastConnectedMs is the unix time of the last successful connect, 0 if never
+	lastConnectedMs atomic.Int64
+	// bufferHighWater is the most bytes queued for any one connection's target
+	bufferHighWater atomic.Int64
+	// lastMessageMs is the unix time of the last message or connect, 0 if never
+	lastMessageMs atomic.Int64
+	// Bytes written to and read from Go-side connections' targets
+	tunnelBytesIn  atomic.Int64
+	tunnelBytesOut atomic.Int64
+	// zeroRTTRejected counts 0-RTT attempts the server refused
+	zeroRTTRejected atomic.Int64
+	// Data payload bytes sent while compression was negotiated, before and after
+	compressPlain atomic.Int64
+	compressWire  atomic.Int64
+	// Connection migrations that kept the connection and that fell back to a reconnect
+	migrations        atomic.Int64
+	migrationFailures atomic.Int64
+}
+
+// statsSnapshot is the JSON shape returned by GetStats
+type statsSnapshot struct {
+	Connected         bool   `json:"connected"`
+	Server            string `json:"server"`
+	ActiveConnections int    `json:"activeConnections"`
+	WarmConnections   int    `json:"warmConnections"`
+	PooledConnections int    `json:"pooledConnections"`
+	BytesSent         int64  `json:"bytesSent"`
+	BytesReceived     int64  `json:"bytesReceived"`
+	MessagesSent      int64  `json:"messagesSent"`
+	MessagesReceived  int64  `json:"messagesReceived"`
+	Connects          int64  `json:"connects"`
+	ConnectFailures   int64  `json:"connectFailures"`
+	ConnectionsOpened int64  `json:"connectionsOpened"`
+	ConnectionsClosed int64  `json:"connectionsClosed"`
+	// BufferHighWaterBytes is the most data ever queued for one connection's target
+	BufferHighWaterBytes int64   `json:"bufferHighWaterBytes"`
+	RTTMs                float64 `json:"rttMs"`
+	QUICVersion          string  `json:"quicVersion,omitempty"`
+	// MsSinceConnect is the time since the last successful connect, -1 if never
+	MsSinceConnect    int64 `json:"msSinceConnect"`
+	VersionNegotiated bool  `json:"versionNegotiated"`
+	ProtocolVersion   int   `json:"protocolVersion"`
+	Throttled         bool  `json:"throttled"`
+	// PathIssue is the broken direction ("uplink" or "downlink") if one is detected
+	PathIssue string `json:"pathIssue,omitempty"`
+	// Connection establishments waiting for a dial slot, total and per priority
+	EstablishQueueDepth      int            `json:"establishQueueDepth"`
+	EstablishQueueByPriority map[string]int `json:"establishQueueByPriority"`
+	// WaitingForStreamSlot is true while a stream open waits on the server's stream limit
+	WaitingForStreamSlot bool `json:"waitingForStreamSlot"`
+	// ConnStreams is the number of connections carried on their own QUIC stream
+	ConnStreams int `json:"connStreams"`
+	// ZeroRTTRejected counts connects whose 0-RTT auth the server refused
+	ZeroRTTRejected int64 `json:"zeroRTTRejected"`
+	// CompressionRatio is how many times smaller compression made data sent, 0 if not negotiated
+	CompressionRatio float64 `json:"compressionRatio"`
+	// Migrations counts network changes survived without reconnecting,
+	// MigrationFailures those that needed a reconnect
+	Migrations        int64 `json:"migrations"`
+	MigrationFailures int64 `json:"migrationFailures"`
+
+	// One-way delays, only meaningful when ClockSynced; accurate to ±ClockSyncErrorMs
+	ClockSynced        bool    `json:"clockSynced"`
+	ServerTimeOffsetMs float64 `json:"serverTimeOffsetMs"`
+	ClockSyncErrorMs   float64 `json:"clockSyncErrorMs"`
+	UplinkDelayMs      float64 `json:"uplinkDelayMs"`
+	DownlinkDelayMs    float64 `json:"downlinkDelayMs"`
+
+	ConnectionsRefusedByReason map[string]int64  `json:"connectionsRefusedByReason"`
+	CircuitBreakers            map[string]string `json:"circuitBreakers"`
+}
+
+// countingReader counts bytes read from the QUIC stream
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n.Add(int64(n))
+	return n, err
+}
+
+// snapshotStats collects the current counters and connection state
+func (c *Client) snapshotStats() statsSnapshot {
+	snap := statsSnapshot{
+		BytesSent:        c.stats.bytesSent.Load(),
+		BytesReceived:    c.stats.bytesReceived.Load(),
+		MessagesSent:     c.stats.messagesSent.Load(),
+		MessagesReceived: c.stats.messagesReceived.Load(),
+		Connects:         c.stats.connects.Load(),
+		ConnectFailures:  c.stats.connectFailures.Load(),
+
+		ConnectionsOpened: c.stats.connectionsOpened.Load(),
+		ConnectionsClosed: c.stats.connectionsClosed.Load(),
+
+		BufferHighWaterBytes: c.stats.bufferHighWater.Load(),
+		ZeroRTTRejected:      c.stats.zeroRTTRejected.Load(),
+		CompressionRatio:     c.compressionRatio(),
+		Migrations:           c.stats.migrations.Load(),
+		MigrationFailures:    c.stats.migrationFailures.Load(),
+
+		ConnectionsRefusedByReason: c.refusalSnapshot(),
+		CircuitBreakers:            c.breakerSnapshot(),
+	}
+	snap.Throttled = c.isThrottled()
+	snap.PathIssue = c.currentPathIssue()
+	snap.MsSinceConnect = -1
+	if at := c.stats.lastConnectedMs.Load(); at > 0 {
+		snap.MsSinceConnect = nowMillis() - at
+	}
+	snap.ProtocolVersion = c.GetProtocolVersion()
+	snap.EstablishQueueDepth, snap.EstablishQueueByPriority = c.establishQueueSnapshot()
+	snap.WaitingForStreamSlot = c.stats.streamWaiters.Load() > 0
+	snap.ConnStreams = c.connStreamCount()
+	snap.WarmConnections = c.warmTotal()
+	snap.PooledConnections = c.relayPoolSize()
+	snap.ClockSynced, snap.ServerTimeOffsetMs, snap.ClockSyncErrorMs,
+		snap.UplinkDelayMs, snap.DownlinkDelayMs = c.delaySnapshot()
+
+	c.clientMutex.RLock()
+	snap.ActiveConnections = len(c.clientConns)
+	c.clientMutex.RUnlock()
+
+	c.serverMutex.Lock()
+	snap.Server = c.serverURL
+	c.serverMutex.Unlock()
+
+	c.quicMutex.Lock()
+	snap.Connected = c.isConnected.Load()
+	if c.quicConn != nil {
+		snap.RTTMs = float64(c.quicConn.ConnectionStats().SmoothedRTT.Microseconds()) / 1000
+		snap.QUICVersion, snap.VersionNegotiated = describeQUICVersion(c.quicConn.ConnectionState().Version)
+	}
+	c.quicMutex.Unlock()
+
+	return snap
+}
+
+// GetStats returns client statistics as a JSON string
+func (c *Client) GetStats() string {
+	data, err := json.Marshal(c.snapshotStats())
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// MetricsText returns client statistics in Prometheus text exposition format
+// Intended for Go services embedding this package that expose a /metrics endpoint.
+func (c *Client) MetricsText() string {
+	snap := c.snapshotStats()
+
+	connected := 0.0
+	if snap.Connected {
+		connected = 1
+	}
+
+	var b strings.Builder
+	writeMetric(&b, "vyx_connected", "Whether the client is connected and authenticated.", "gauge", connected)
+	writeMetric(&b, "vyx_active_connections", "Number of tunneled connections currently relayed.", "gauge", float64(snap.ActiveConnections))
+	writeMetric(&b, "vyx_sent_bytes_total", "Bytes written to the QUIC stream.", "counter", float64(snap.BytesSent))
+	writeMetric(&b, "vyx_received_bytes_total", "Bytes read from the QUIC stream.", "counter", float64(snap.BytesReceived))
+	writeMetric(&b, "vyx_sent_messages_total", "Protocol messages sent to the server.", "counter", float64(snap.MessagesSent))
+	writeMetric(&b, "vyx_received_messages_total", "Protocol messages received from the server.", "counter", float64(snap.MessagesReceived))
+	writeMetric(&b, "vyx_connects_total", "Successful connections to the server.", "counter", float64(snap.Connects))
+	writeMetric(&b, "vyx_connect_failures_total", "Failed connection attempts.", "counter", float64(snap.ConnectFailures))
+	writeMetric(&b, "vyx_tunnel_connections_opened_total", "Tunneled connections requested.", "counter", float64(snap.ConnectionsOpened))
+	writeMetric(&b, "vyx_tunnel_connections_closed_total", "Tunneled connections closed.", "counter", float64(snap.ConnectionsClosed))
+	writeMetric(&b, "vyx_conn_buffer_high_water_bytes", "Most data queued for one connection's target.", "gauge", float64(snap.BufferHighWaterBytes))
+	writeMetric(&b, "vyx_rtt_seconds", "Smoothed RTT of the QUIC connection.", "gauge", snap.RTTMs/1000)
+
+	fmt.Fprintf(&b, "# HELP vyx_connections_refused_total Connections refused by local policy.\n")
+	fmt.Fprintf(&b, "# TYPE vyx_connections_refused_total counter\n")
+	for reason, n := range snap.ConnectionsRefusedByReason {
+		fmt.Fprintf(&b, "vyx_connections_refused_total{reason=%q} %d\n", reason, n)
+	}
+
+	fmt.Fprintf(&b, "# HELP vyx_establish_queue_depth Connection establishments waiting for a dial slot.\n")
+	fmt.Fprintf(&b, "# TYPE vyx_establish_queue_depth gauge\n")
+	for priority, n := range snap.EstablishQueueByPriority {
+		fmt.Fprintf(&b, "vyx_establish_queue_depth{priority=%q} %d\n", priority, n)
+	}
+	return b.String()
+}
+
+// writeMetric appends one metric with its HELP and TYPE lines
+func writeMetric(b *strings.Builder, name, help, metricType string, value float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(b, "# TYPE %s %s\n", name, metricType)
+	fmt.Fprintf(b, "%s %s\n", name, strconv.FormatFloat(value, 'g', -1, 64))
+}
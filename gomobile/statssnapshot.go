@@ -0,0 +1,123 @@
+package vyxclient
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// StatsSnapshotCallback is an optional extension of Callback
+// Implement it on your Callback to receive periodic interval statistics.
+type StatsSnapshotCallback interface {
+	// OnStatsSnapshot is called every snapshot interval with JSON holding the
+	// counter deltas since the previous snapshot
+	OnStatsSnapshot(json string)
+}
+
+// intervalSnapshot is the JSON shape passed to OnStatsSnapshot
+type intervalSnapshot struct {
+	StartMs           int64 `json:"startMs"`
+	EndMs             int64 `json:"endMs"`
+	BytesSent         int64 `json:"bytesSent"`
+	BytesReceived     int64 `json:"bytesReceived"`
+	MessagesSent      int64 `json:"messagesSent"`
+	MessagesReceived  int64 `json:"messagesReceived"`
+	ConnectionsOpened int64 `json:"connectionsOpened"`
+	ConnectionsClosed int64 `json:"connectionsClosed"`
+	Reconnects        int64 `json:"reconnects"`
+	ConnectFailures   int64 `json:"connectFailures"`
+}
+
+// counterValues is a point-in-time copy of the lifetime counters
+type counterValues struct {
+	bytesSent, bytesReceived, messagesSent, messagesReceived int64
+	opened, closed, connects, connectFailures                int64
+}
+
+// SetStatsSnapshotInterval enables periodic OnStatsSnapshot callbacks
+// Each snapshot covers the counters since the previous one; lifetime totals
+// in GetStats are unaffected. intervalMillis <= 0 disables.
+func (c *Client) SetStatsSnapshotInterval(intervalMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.StatsSnapshotIntervalMs = intervalMillis
+	})
+}
+
+// statsSnapshotLoop emits interval snapshots while the client runs
+func (c *Client) statsSnapshotLoop() {
+	base := c.loadCounters()
+	start := time.Now()
+
+	for c.shouldRun.Load() {
+		interval := time.Duration(c.getConfig().StatsSnapshotIntervalMs) * time.Millisecond
+		wait := interval
+		if interval <= 0 {
+			wait = 5 * time.Second // re-check config periodically
+		} else if next := start.Add(interval); time.Now().Before(next) {
+			wait = time.Until(next)
+		} else {
+			wait = 0
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-c.ctx.Done():
+			return
+		}
+
+		if interval <= 0 {
+			// Disabled: keep the next interval from covering the gap
+			base = c.loadCounters()
+			start = time.Now()
+			continue
+		}
+		if time.Since(start) < interval {
+			continue
+		}
+
+		current := c.loadCounters()
+		end := time.Now()
+		c.emitStatsSnapshot(start, end, base, current)
+		base = current
+		start = end
+	}
+}
+
+// loadCounters reads the lifetime counters
+func (c *Client) loadCounters() counterValues {
+	return counterValues{
+		bytesSent:        c.stats.bytesSent.Load(),
+		bytesReceived:    c.stats.bytesReceived.Load(),
+		messagesSent:     c.stats.messagesSent.Load(),
+		messagesReceived: c.stats.messagesReceived.Load(),
+		opened:           c.stats.connectionsOpened.Load(),
+		closed:           c.stats.connectionsClosed.Load(),
+		connects:         c.stats.connects.Load(),
+		connectFailures:  c.stats.connectFailures.Load(),
+	}
+}
+
+// emitStatsSnapshot reports the deltas between two counter readings
+func (c *Client) emitStatsSnapshot(start, end time.Time, base, current counterValues) {
+	cb, ok := c.callback.(StatsSnapshotCallback)
+	if !ok {
+		return
+	}
+
+	snap := intervalSnapshot{
+		StartMs:           start.UnixMilli(),
+		EndMs:             end.UnixMilli(),
+		BytesSent:         current.bytesSent - base.bytesSent,
+		BytesReceived:     current.bytesReceived - base.bytesReceived,
+		MessagesSent:      current.messagesSent - base.messagesSent,
+		MessagesReceived:  current.messagesReceived - base.messagesReceived,
+		ConnectionsOpened: current.opened - base.opened,
+		ConnectionsClosed: current.closed - base.closed,
+		Reconnects:        current.connects - base.connects,
+		ConnectFailures:   current.connectFailures - base.connectFailures,
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return
+	}
+	cb.OnStatsSnapshot(string(data))
+}
@@ -0,0 +1,48 @@
+package vyxclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// SetStreamOpenTimeout bounds how long opening a QUIC stream may wait for
+// the server to grant a stream slot. When the server's stream limit is
+// reached the open queues until a slot frees up or the timeout expires.
+func (c *Client) SetStreamOpenTimeout(timeoutMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.StreamOpenTimeoutMs = timeoutMillis
+	})
+}
+
+// openStream opens a stream on conn, waiting for a slot if the limit is reached
+// While waiting, GetStats reports waitingForStreamSlot.
+func (c *Client) openStream(conn *quic.Conn) (*quic.Stream, error) {
+	stream, err := conn.OpenStream()
+	if err == nil {
+		return stream, nil
+	}
+
+	var limitErr quic.StreamLimitReachedError
+	if !errors.As(err, &limitErr) {
+		return nil, err
+	}
+
+	timeout := time.Duration(c.getConfig().StreamOpenTimeoutMs) * time.Millisecond
+	c.log(fmt.Sprintf("Stream limit reached, waiting up to %v for a stream slot", timeout))
+
+	c.stats.streamWaiters.Add(1)
+	defer c.stats.streamWaiters.Add(-1)
+
+	ctx, cancel := context.WithTimeout(c.ctx, timeout)
+	defer cancel()
+
+	stream, err = conn.OpenStreamSync(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("no stream slot available within %v", timeout)
+	}
+	return stream, err
+}
@@ -0,0 +1,102 @@
+package vyxclient
+
+import (
+	"sync"
+	"time"
+)
+
+// One-way delay estimation
+//
+// With SetMessageTimestamps(true) every outgoing message carries the send
+// time ("ts", unix milliseconds) and the server is expected to do the same.
+// The server clock offset is estimated from PingSync round trips, keeping the
+// sample with the lowest RTT (as NTP does): the offset is accurate to within
+// half of that RTT, reported as clockSyncErrorMs in GetStats. Uplink and
+// downlink delays are derived from timestamps corrected by this offset, so
+// they are only as good as the clock sync; on asymmetric links the error
+// shows up as a bias of at most clockSyncErrorMs in each direction.
+
+// delaySmoothing is the EWMA weight of a new delay sample
+const delaySmoothing = 0.125
+
+// timeSync tracks the server clock offset and one-way delay estimates
+type timeSync struct {
+	mu         sync.Mutex
+	synced     bool
+	offsetMs   float64 // server clock minus local clock
+	bestRTTMs  float64
+	uplinkMs   float64
+	downlinkMs float64
+}
+
+// SetMessageTimestamps stamps outgoing messages with the send time
+// Required, along with server support, for one-way delay estimates.
+func (c *Client) SetMessageTimestamps(enabled bool) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.MessageTimestamps = enabled
+	})
+}
+
+// nowMillis returns the local wall clock in unix milliseconds
+func nowMillis() int64 {
+	return time.Now().UnixMilli()
+}
+
+// recordTimeSample updates the clock offset from a ping round trip
+// sent/received are local unix ms, serverTS is the pong's timestamp.
+func (c *Client) recordTimeSample(sent int64, received int64, serverTS int64) {
+	if serverTS == 0 {
+		return
+	}
+
+	rtt := float64(received - sent)
+	sample := float64(serverTS) - float64(sent+received)/2
+
+	c.timeSync.mu.Lock()
+	defer c.timeSync.mu.Unlock()
+
+	if !c.timeSync.synced || rtt <= c.timeSync.bestRTTMs {
+		c.timeSync.synced = true
+		c.timeSync.offsetMs = sample
+		c.timeSync.bestRTTMs = rtt
+	}
+
+	uplink := float64(serverTS) - c.timeSync.offsetMs - float64(sent)
+	c.timeSync.uplinkMs = smoothDelay(c.timeSync.uplinkMs, uplink)
+}
+
+// recordDownlink updates the downlink delay from a received message's timestamp
+func (c *Client) recordDownlink(serverTS int64) {
+	if serverTS == 0 {
+		return
+	}
+	received := nowMillis()
+
+	c.timeSync.mu.Lock()
+	defer c.timeSync.mu.Unlock()
+
+	if !c.timeSync.synced {
+		return
+	}
+	downlink := float64(received) - (float64(serverTS) - c.timeSync.offsetMs)
+	c.timeSync.downlinkMs = smoothDelay(c.timeSync.downlinkMs, downlink)
+}
+
+// smoothDelay folds a new sample into an EWMA, clamping negative samples caused by clock error
+func smoothDelay(current float64, sample float64) float64 {
+	if sample < 0 {
+		sample = 0
+	}
+	if current == 0 {
+		return sample
+	}
+	return current + delaySmoothing*(sample-current)
+}
+
+// delaySnapshot returns (synced, offset, error bound, uplink, downlink) in milliseconds
+func (c *Client) delaySnapshot() (bool, float64, float64, float64, float64) {
+	c.timeSync.mu.Lock()
+	defer c.timeSync.mu.Unlock()
+	return c.timeSync.synced, c.timeSync.offsetMs, c.timeSync.bestRTTMs / 2,
+		c.timeSync.uplinkMs, c.timeSync.downlinkMs
+}
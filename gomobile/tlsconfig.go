@@ -0,0 +1,101 @@
+package vyxclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// QUIC only runs over TLS 1.3, so that's the minimum offered and the only
+// cipher suites that matter are the TLS 1.3 ones. Go doesn't let a client
+// restrict which TLS 1.3 suites it offers, so SetTLSCipherSuites is enforced
+// on the server's choice instead: a handshake settling on another suite is
+// aborted before any data is exchanged.
+
+// tls13CipherSuites are the suites accepted by SetTLSCipherSuites
+var tls13CipherSuites = map[string]uint16{
+	"TLS_AES_128_GCM_SHA256":       tls.TLS_AES_128_GCM_SHA256,
+	"TLS_AES_256_GCM_SHA384":       tls.TLS_AES_256_GCM_SHA384,
+	"TLS_CHACHA20_POLY1305_SHA256": tls.TLS_CHACHA20_POLY1305_SHA256,
+}
+
+// SetTLSCipherSuites restricts the TLS 1.3 cipher suites a server may choose
+// suites is a comma-separated list of TLS 1.3 suite names, e.g.
+// "TLS_AES_128_GCM_SHA256,TLS_AES_256_GCM_SHA384" to exclude ChaCha20 for
+// FIPS-style requirements; empty allows all. Takes effect on the next connect.
+// Returns error message or empty string on success
+func (c *Client) SetTLSCipherSuites(suites string) string {
+	normalized, err := normalizeCipherSuites(suites)
+	if err != nil {
+		return err.Error()
+	}
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.TLSCipherSuites = normalized
+	})
+	return ""
+}
+
+// SetTLSConfig replaces the TLS configuration used to connect, nil restores the default
+// For advanced Go callers: the config is used as given (cloned), so server
+// name, verification, pinning, SetTLSCipherSuites and session resumption are
+// all up to it. Only the ALPN protocol is filled in if NextProtos is empty.
+// Takes effect on the next connect.
+func (c *Client) SetTLSConfig(config *tls.Config) {
+	if config != nil {
+		config = config.Clone()
+	}
+	c.configMutex.Lock()
+	c.tlsOverride = config
+	c.configMutex.Unlock()
+}
+
+// tlsConfigOverride returns a copy of the config from SetTLSConfig, nil if unset
+func (c *Client) tlsConfigOverride() *tls.Config {
+	c.configMutex.RLock()
+	defer c.configMutex.RUnlock()
+
+	if c.tlsOverride == nil {
+		return nil
+	}
+	config := c.tlsOverride.Clone()
+	if len(config.NextProtos) == 0 {
+		config.NextProtos = []string{"vyx-proxy"}
+	}
+	return config
+}
+
+// normalizeCipherSuites validates a comma-separated suite list and tidies it up
+func normalizeCipherSuites(suites string) (string, error) {
+	var names []string
+	for _, name := range strings.Split(suites, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := tls13CipherSuites[name]; !ok {
+			return "", fmt.Errorf("unknown TLS 1.3 cipher suite %q", name)
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ","), nil
+}
+
+// cipherSuiteVerifier returns a VerifyConnection callback rejecting suites not in suites
+// Returns nil if suites allows all. VerifyConnection also runs on resumed
+// sessions, so tickets from before the list changed can't bypass it.
+func cipherSuiteVerifier(suites string) func(tls.ConnectionState) error {
+	suites, _ = normalizeCipherSuites(suites)
+	if suites == "" {
+		return nil
+	}
+	allowed := make(map[uint16]bool)
+	for _, name := range strings.Split(suites, ",") {
+		allowed[tls13CipherSuites[name]] = true
+	}
+	return func(state tls.ConnectionState) error {
+		if !allowed[state.CipherSuite] {
+			return fmt.Errorf("server chose cipher suite %s, allowed are %s", tls.CipherSuiteName(state.CipherSuite), suites)
+		}
+		return nil
+	}
+}
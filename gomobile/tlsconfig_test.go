@@ -0,0 +1,39 @@
+package vyxclient
+
+import (
+	"crypto/tls"
+	"strings"
+	"testing"
+)
+
+// TestBuildTLSConfigRejectsTLS12 checks that a server stuck on TLS 1.2 is refused
+func TestBuildTLSConfigRejectsTLS12(t *testing.T) {
+	cert, _ := selfSignedCert(t)
+	c, _ := newTestClient(t)
+	defer c.Stop()
+
+	tests := []struct {
+		name       string
+		maxVersion uint16
+		wantErr    bool
+	}{
+		{"TLS 1.2 only", tls.VersionTLS12, true},
+		{"TLS 1.3", tls.VersionTLS13, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverConf := &tls.Config{Certificates: []tls.Certificate{cert}, MaxVersion: tt.maxVersion}
+			// localhost skips chain verification, so only the version matters
+			err := handshake(t, serverConf, c.buildTLSConfig("localhost:443"))
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "version") {
+					t.Fatalf("handshake error = %v, want a protocol version error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("handshake failed: %v", err)
+			}
+		})
+	}
+}
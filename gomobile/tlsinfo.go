@@ -0,0 +1,93 @@
+package vyxclient
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+
+	"github.com/quic-go/quic-go"
+)
+
+// offeredQUICVersions are the QUIC versions offered, most preferred first
+var offeredQUICVersions = []quic.Version{quic.Version1, quic.Version2}
+
+// ConnectedDetailsCallback is an optional extension of Callback
+// OnConnectedDetails fires right after OnConnected with what the handshake
+// negotiated, as the same JSON GetTLSInfo returns plus the server address,
+// e.g. {"connected":true,"tlsVersion":"TLS 1.3","alpn":"vyx-proxy",...}.
+type ConnectedDetailsCallback interface {
+	OnConnectedDetails(info string)
+}
+
+// tlsInfo is the JSON shape returned by GetTLSInfo
+type tlsInfo struct {
+	Connected   bool   `json:"connected"`
+	Server      string `json:"server,omitempty"`
+	TLSVersion  string `json:"tlsVersion,omitempty"`
+	CipherSuite string `json:"cipherSuite,omitempty"`
+	ServerName  string `json:"serverName,omitempty"`
+	ALPN        string `json:"alpn,omitempty"`
+	DidResume   bool   `json:"didResume"`
+	Used0RTT    bool   `json:"used0RTT"`
+	// QUICVersion is the negotiated version; VersionNegotiated is true when it
+	// differs from the version first offered (a mismatch that may cost a round trip)
+	QUICVersion       string `json:"quicVersion,omitempty"`
+	VersionNegotiated bool   `json:"versionNegotiated"`
+}
+
+// GetTLSInfo returns TLS and QUIC details of the current connection as JSON
+func (c *Client) GetTLSInfo() string {
+	data, err := json.Marshal(c.currentTLSInfo())
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// notifyConnectedDetails logs and reports what the new connection negotiated
+func (c *Client) notifyConnectedDetails() {
+	info := c.currentTLSInfo()
+	if !info.Connected {
+		return
+	}
+	info.Server = c.currentServer()
+	c.log(fmt.Sprintf("Negotiated %s, ALPN %q, QUIC %s", info.TLSVersion, info.ALPN, info.QUICVersion))
+
+	cb, ok := c.callback.(ConnectedDetailsCallback)
+	if !ok {
+		return
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return
+	}
+	cb.OnConnectedDetails(string(data))
+}
+
+// currentTLSInfo describes the current connection, zero if not connected
+func (c *Client) currentTLSInfo() tlsInfo {
+	info := tlsInfo{}
+
+	c.quicMutex.Lock()
+	conn := c.quicConn
+	c.quicMutex.Unlock()
+
+	if conn != nil {
+		state := conn.ConnectionState()
+		info.Connected = true
+		info.TLSVersion = tls.VersionName(state.TLS.Version)
+		info.CipherSuite = tls.CipherSuiteName(state.TLS.CipherSuite)
+		info.ServerName = state.TLS.ServerName
+		info.ALPN = state.TLS.NegotiatedProtocol
+		info.DidResume = state.TLS.DidResume
+		info.Used0RTT = state.Used0RTT
+		info.QUICVersion, info.VersionNegotiated = describeQUICVersion(state.Version)
+	}
+	return info
+}
+
+// describeQUICVersion names a negotiated version and reports whether it
+// differs from the one first offered
+func describeQUICVersion(v quic.Version) (string, bool) {
+	return v.String(), v != offeredQUICVersions[0]
+}
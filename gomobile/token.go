@@ -0,0 +1,72 @@
+package vyxclient
+
+import (
+	"fmt"
+	"time"
+)
+
+// TokenProvider supplies the API token right before each authentication
+// Implementations should return quickly; a provider that exceeds the timeout
+// (see SetTokenProviderTimeout) is ignored for that attempt and the last
+// known token is used instead.
+type TokenProvider interface {
+	GetToken() string
+}
+
+// SetTokenProvider installs a provider consulted before every (re)connect
+// Pass nil to go back to the token given to NewClient.
+func (c *Client) SetTokenProvider(provider TokenProvider) {
+	c.tokenMutex.Lock()
+	c.tokenProvider = provider
+	c.tokenMutex.Unlock()
+	c.releaseTokenHold()
+}
+
+// SetToken replaces the API token used for the next authentication
+// Reconnection held after a permanent auth failure resumes with the new token.
+func (c *Client) SetToken(token string) {
+	c.tokenMutex.Lock()
+	c.apiToken = token
+	c.tokenMutex.Unlock()
+	c.releaseTokenHold()
+}
+
+// SetTokenProviderTimeout sets how long to wait for the TokenProvider
+func (c *Client) SetTokenProviderTimeout(timeoutMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.TokenProviderTimeoutMs = timeoutMillis
+	})
+}
+
+// currentToken returns the token to authenticate with
+func (c *Client) currentToken() string {
+	c.tokenMutex.Lock()
+	provider := c.tokenProvider
+	fallback := c.apiToken
+	c.tokenMutex.Unlock()
+
+	if provider == nil {
+		return fallback
+	}
+
+	tokenChan := make(chan string, 1)
+	go func() {
+		tokenChan <- provider.GetToken()
+	}()
+
+	timeout := time.Duration(c.getConfig().TokenProviderTimeoutMs) * time.Millisecond
+	select {
+	case token := <-tokenChan:
+		if token == "" {
+			c.log("Token provider returned empty token, using last known token")
+			return fallback
+		}
+		c.tokenMutex.Lock()
+		c.apiToken = token
+		c.tokenMutex.Unlock()
+		return token
+	case <-time.After(timeout):
+		c.log(fmt.Sprintf("Token provider timed out after %v, using last known token", timeout))
+		return fallback
+	}
+}
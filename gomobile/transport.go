@@ -0,0 +1,106 @@
+package vyxclient
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/quic-go/quic-go"
+)
+
+// Transport lifecycle
+//
+// The client owns a single quic.Transport (and the UDP socket behind it) for
+// its whole lifetime. The socket is created lazily on the first connection
+// attempt, or earlier if the app calls GetSocketFD, and every reconnect dials
+// a new QUIC connection on the same transport. Closing a QUIC connection does
+// not close the socket; only Stop() does. On Android this means the socket
+// only has to be passed to VpnService.protect() once. MigrateConnection is
+// the exception: it moves the live connection to a new socket, which then
+// becomes the shared one.
+
+// ensureTransport returns the shared QUIC transport, creating it on first use
+func (c *Client) ensureTransport() (*quic.Transport, error) {
+	c.transportMutex.Lock()
+	defer c.transportMutex.Unlock()
+
+	if c.transport != nil {
+		return c.transport, nil
+	}
+
+	packetConn, err := c.newPacketConn()
+	if err != nil {
+		return nil, err
+	}
+
+	c.applySocketOptions(packetConn)
+
+	c.packetConn = packetConn
+	c.transport = &quic.Transport{Conn: packetConn}
+	c.log(fmt.Sprintf("Created QUIC transport on %s", packetConn.LocalAddr()))
+
+	return c.transport, nil
+}
+
+// applySocketOptions applies socket settings such as DSCP to a new socket
+func (c *Client) applySocketOptions(packetConn net.PacketConn) {
+	if udpConn, ok := packetConn.(*net.UDPConn); ok {
+		if dscp := c.getConfig().DSCP; dscp != 0 {
+			if err := applyDSCP(udpConn, dscp); err != nil {
+				c.logError(err.Error())
+			}
+		}
+	}
+}
+
+// closeTransport closes the shared transport and its UDP socket
+func (c *Client) closeTransport() {
+	c.transportMutex.Lock()
+	defer c.transportMutex.Unlock()
+
+	if c.transport != nil {
+		c.transport.Close()
+		c.transport = nil
+	}
+	if c.packetConn != nil {
+		c.packetConn.Close()
+		c.packetConn = nil
+	}
+}
+
+// GetSocketFD returns the file descriptor of the UDP socket used for QUIC
+// The socket is created if it doesn't exist yet, so Android apps can call this
+// before Start() and pass the result to VpnService.protect().
+// Returns -1 if the socket could not be created.
+func (c *Client) GetSocketFD() int {
+	if _, err := c.ensureTransport(); err != nil {
+		c.logError(fmt.Sprintf("Failed to get socket: %v", err))
+		return -1
+	}
+
+	c.transportMutex.Lock()
+	defer c.transportMutex.Unlock()
+
+	udpConn, ok := c.packetConn.(*net.UDPConn)
+	if !ok {
+		return -1
+	}
+
+	rawConn, err := udpConn.SyscallConn()
+	if err != nil {
+		return -1
+	}
+
+	fd := -1
+	rawConn.Control(func(s uintptr) {
+		fd = int(s)
+	})
+	return fd
+}
+
+// PacketConn returns the UDP socket shared by all QUIC connections, or nil
+// Note: This method is not exported for Go Mobile (net.PacketConn can't be bound)
+func (c *Client) PacketConn() net.PacketConn {
+	c.transportMutex.Lock()
+	defer c.transportMutex.Unlock()
+	return c.packetConn
+}
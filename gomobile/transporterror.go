@@ -0,0 +1,49 @@
+package vyxclient
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/quic-go/quic-go"
+)
+
+// TransportErrorCallback is an optional extension of Callback
+// OnTransportError fires when the server closes the QUIC connection with an
+// error, before the client reconnects. code is the application error code the
+// server passed to CloseWithError (e.g. rate limit, maintenance), or the QUIC
+// transport error code for protocol-level failures; reason is the server's
+// message, or the transport error's name prefixed with "transport: ".
+type TransportErrorCallback interface {
+	OnTransportError(code int, reason string)
+}
+
+// remoteCloseError extracts the code and reason of a close by the server
+// Returns false if err isn't one, e.g. a timeout or a close by the client.
+func remoteCloseError(err error) (int, string, bool) {
+	var appErr *quic.ApplicationError
+	if errors.As(err, &appErr) && appErr.Remote {
+		return int(appErr.ErrorCode), appErr.ErrorMessage, true
+	}
+
+	var transportErr *quic.TransportError
+	if errors.As(err, &transportErr) && transportErr.Remote {
+		reason := "transport: " + transportErr.ErrorCode.String()
+		if transportErr.ErrorMessage != "" {
+			reason += ": " + transportErr.ErrorMessage
+		}
+		return int(transportErr.ErrorCode), reason, true
+	}
+	return 0, "", false
+}
+
+// notifyTransportError reports why the server closed the connection, if it said
+func (c *Client) notifyTransportError(err error) {
+	code, reason, ok := remoteCloseError(err)
+	if !ok {
+		return
+	}
+	c.logError(fmt.Sprintf("Server closed the connection: code %d (%s)", code, reason))
+	if cb, ok := c.callback.(TransportErrorCallback); ok {
+		cb.OnTransportError(code, reason)
+	}
+}
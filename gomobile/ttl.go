@@ -0,0 +1,103 @@
+package vyxclient
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// reasonTTLExpired is reported when a connection outlives its TTL
+const reasonTTLExpired = "ttl_expired"
+
+// ConnectionClosedCallback is an optional extension of Callback
+// OnConnectionClosed fires when the SDK itself closes a connection, with
+// the reason (e.g. "ttl_expired").
+type ConnectionClosedCallback interface {
+	OnConnectionClosed(id string, reason string)
+}
+
+// ttlTimers holds the lifetime timer of each connection that has a TTL
+type ttlTimers struct {
+	mu     sync.Mutex
+	timers map[string]*time.Timer
+}
+
+// SetDefaultConnectionTTL force-closes connections after ttlMillis regardless
+// of activity. Unlike an idle timeout this also closes busy connections.
+// Applies to connections opened afterwards; 0 disables.
+// OpenConnectionWithOptions can override it per connection.
+func (c *Client) SetDefaultConnectionTTL(ttlMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.DefaultConnectionTTLMs = ttlMillis
+	})
+}
+
+// startTTL arms the lifetime timer of a connection, ttl <= 0 does nothing
+func (c *Client) startTTL(id string, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	timer := time.AfterFunc(ttl, func() {
+		c.expireConnection(id, ttl)
+	})
+
+	c.ttls.mu.Lock()
+	if c.ttls.timers == nil {
+		c.ttls.timers = make(map[string]*time.Timer)
+	}
+	if old, ok := c.ttls.timers[id]; ok {
+		old.Stop()
+	}
+	c.ttls.timers[id] = timer
+	c.ttls.mu.Unlock()
+}
+
+// stopTTL cancels the lifetime timer of a closed connection
+func (c *Client) stopTTL(id string) {
+	c.ttls.mu.Lock()
+	if timer, ok := c.ttls.timers[id]; ok {
+		timer.Stop()
+		delete(c.ttls.timers, id)
+	}
+	c.ttls.mu.Unlock()
+}
+
+// expireConnection closes a connection whose TTL elapsed
+func (c *Client) expireConnection(id string, ttl time.Duration) {
+	c.ttls.mu.Lock()
+	_, armed := c.ttls.timers[id]
+	delete(c.ttls.timers, id)
+	c.ttls.mu.Unlock()
+
+	if !armed {
+		return // closed normally while the timer fired
+	}
+
+	c.log(fmt.Sprintf("Connection %s reached its TTL of %v, closing", id, ttl))
+	c.closeWithReason(id, reasonTTLExpired)
+}
+
+// closeWithReason closes a connection on the SDK's initiative
+func (c *Client) closeWithReason(id string, reason string) {
+	if !c.closeConnection(id, true) {
+		// Not relayed by the SDK: close it on the server and end it locally
+		c.sendMessage(&Message{Type: "close", ID: id, Data: reason})
+		c.markClosed(id)
+		c.connectionEnded(id)
+	}
+
+	if cb, ok := c.callback.(ConnectionClosedCallback); ok {
+		cb.OnConnectionClosed(id, reason)
+	}
+}
+
+// connectionEnded drops per-connection bookkeeping once a connection is closed
+func (c *Client) connectionEnded(id string) {
+	c.forgetConnectionPriority(id)
+	c.stopTTL(id)
+	c.forgetWarm(id)
+	c.DisableConnectionLiveness(id)
+	c.forgetConnectionKey(id)
+	c.closeConnStream(id)
+}
@@ -2,14 +2,19 @@ package vyxclient
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/quic-go/quic-go"
@@ -41,12 +46,47 @@ type Message struct {
 	ID   string `json:"id"`
 	Addr string `json:"addr,omitempty"`
 	Data string `json:"data,omitempty"`
+	// Nonce correlates an auth request with its response
+	Nonce string `json:"nonce,omitempty"`
+	// Priority is the QoS class of a connection (PriorityInteractive, PriorityBulk)
+	Priority int `json:"priority,omitempty"`
+	// Ref correlates a request with its response (echoed by the server)
+	Ref string `json:"ref,omitempty"`
+	// TS is the sender's wall clock in unix milliseconds (optional)
+	TS int64 `json:"ts,omitempty"`
+	// Caps lists optional features offered (auth) or accepted (auth_success)
+	Caps []string `json:"caps,omitempty"`
+	// Proto is the sender's protocol version (auth, auth_success)
+	Proto int `json:"proto,omitempty"`
+	// Enc is "deflate" if a data payload is compressed
+	Enc string `json:"enc,omitempty"`
+
+	// raw is the payload of a data message sent or received as a binary frame
+	raw []byte
 }
 
 // Connection represents a TCP connection to target
 type Connection struct {
 	conn     net.Conn
 	dataChan chan []byte
+	ctx      context.Context
+	cancel   context.CancelFunc
+	limiter  *tokenBucket
+	// Bytes queued on dataChan and written to conn, for FlushAll
+	enqueued atomic.Int64
+	written  atomic.Int64
+	// sent counts bytes read from conn and sent to the server
+	sent atomic.Int64
+	// relays tracks the goroutines or pool slot servicing conn
+	relays sync.WaitGroup
+	// stopAutoClose disarms closing conn when ctx is canceled
+	stopAutoClose func() bool
+	// idleTimeout closes conn after no data either way, 0 disables
+	idleTimeout time.Duration
+	// lastActive is when data last moved, in unix milliseconds
+	lastActive atomic.Int64
+	// fin tracks half-close of connections with their own stream
+	fin halfClose
 }
 
 // Client is the main QUIC client for Android (exported for Go Mobile)
@@ -58,22 +98,74 @@ type Client struct {
 	callback            Callback
 	quicConn            *quic.Conn
 	quicStream          *quic.Stream
+	streamWriter        *coalescingWriter
 	quicMutex           sync.Mutex
 	clientConns         map[string]*Connection
 	clientMutex         sync.RWMutex
 	ctx                 context.Context
 	cancel              context.CancelFunc
-	isConnected         bool
-	shouldRun           bool
+	isConnected         atomic.Bool
+	shouldRun           atomic.Bool
 	consecutiveFailures int
 	retryMutex          sync.Mutex
 	serverList          []string
+	serverSpec          string               // servers as configured, possibly comma-separated
+	activeServer        string               // server of the current connection
+	quarantined         map[string]time.Time // servers skipped until the time given
 	currentServerIdx    int
 	serverMutex         sync.Mutex
+	transport           *quic.Transport
+	packetConn          net.PacketConn
+	customPacketConn    net.PacketConn // from SetPacketConn, used by the next transport
+	transportMutex      sync.Mutex
+	migrationMutex      sync.Mutex // serializes MigrateConnection
+	config              clientConfig
+	tlsOverride         *tls.Config // from SetTLSConfig, guarded by configMutex
+	configMutex         sync.RWMutex
+	sampler             logSampler
+	sendSched           sendScheduler
+	connPriority        map[string]int
+	priorityMutex       sync.Mutex
+	stats               clientStats
+	lastFailureReason   string
+	reconnectReason     string
+	tokenProvider       TokenProvider
+	tokenMutex          sync.Mutex
+	pending             pendingRequests
+	internal            internalConns
+	closed              closedConns
+	refusals            refusalCounters
+	globalLimiter       *tokenBucket
+	limiterMutex        sync.Mutex
+	sessions            sessionCache
+	flapTimes           []time.Time
+	ttls                ttlTimers
+	establish           establishGate
+	timeSync            timeSync
+	breakers            circuitBreakers
+	warm                warmPool
+	observer            atomic.Pointer[observerSink]
+	dispatcher          *callbackDispatcher
+	reconnect           reconnectGate
+	caps                negotiatedCaps
+	liveness            livenessProbes
+	protoVersion        atomic.Int32
+	awaitingToken       atomic.Bool
+	stopping            atomic.Bool
+	paused              atomic.Bool
+	resumes             pendingResumes
+	errLimit            errorLimiter
+	connStreams         connStreams
+	meters              endedMeters
+	path                pathMonitor
+	hist                *relayHistograms
+	relayPool           relayPool
+	connKeys            connectionKeys
 }
 
 // NewClient creates a new QUIC client instance
-// serverURL: server address (e.g., "api.vyx.network:8443")
+// serverURL: server address (e.g., "api.vyx.network:8443" or "[2001:db8::1]:8443"; port defaults to 8443),
+// or a comma-separated list of them tried in order
 // apiToken: authentication token from dashboard
 // clientType: "android_sdk" or similar
 // metadata: JSON string with device info
@@ -81,13 +173,39 @@ type Client struct {
 func NewClient(serverURL string, apiToken string, clientType string, metadata string, callback Callback) *Client {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// Build server list with primary and fallbacks
-	serverList := []string{
-		serverURL,
+	serverList := buildServerList(serverURL)
+	c := &Client{
+		serverURL:   serverList[0],
+		serverSpec:  serverURL,
+		apiToken:    apiToken,
+		clientType:  clientType,
+		metadata:    metadata,
+		callback:    callback,
+		clientConns: make(map[string]*Connection),
+		ctx:         ctx,
+		cancel:      cancel,
+		serverList:  serverList,
+		config:      defaultConfig(),
+		hist:        newRelayHistograms(),
+	}
+	c.shouldRun.Store(true)
+	return c
+}
+
+// buildServerList returns the configured servers followed by the fallbacks
+// servers may be a comma-separated list in priority order.
+func buildServerList(servers string) []string {
+	var serverList []string
+	for _, s := range strings.Split(servers, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			serverList = append(serverList, s)
+		}
+	}
+	serverList = append(serverList,
 		"us.vyx.network:8443",
 		"eu.vyx.network:8443",
 		"proxy.vyx.network:8443",
-	}
+	)
 
 	// Remove duplicates
 	uniqueServers := make([]string, 0, len(serverList))
@@ -98,133 +216,261 @@ func NewClient(serverURL string, apiToken string, clientType string, metadata st
 			uniqueServers = append(uniqueServers, s)
 		}
 	}
-
-	return &Client{
-		serverURL:   serverURL,
-		apiToken:    apiToken,
-		clientType:  clientType,
-		metadata:    metadata,
-		callback:    callback,
-		clientConns: make(map[string]*Connection),
-		ctx:         ctx,
-		cancel:      cancel,
-		shouldRun:   true,
-		serverList:  uniqueServers,
-	}
+	return uniqueServers
 }
 
 // Start begins the connection loop with automatic reconnection
 func (c *Client) Start() {
+	c.logCallbackCapabilities()
+	c.startDispatcher()
 	go c.connectionLoop()
+	go c.healthCheckLoop()
+	go c.statsSnapshotLoop()
+	go c.warmPoolLoop()
+	go c.pathMonitorLoop()
+	go c.watchdogLoop()
 }
 
 // Stop disconnects and stops reconnection attempts
 func (c *Client) Stop() {
-	c.shouldRun = false
+	c.shouldRun.Store(false)
 	c.cancel()
 	c.disconnect()
+	c.closeTransport()
 }
 
 // SendMessage sends a message to the server
+// SendMessageResult reports failures with an error code instead.
 // Returns error message or empty string on success
 func (c *Client) SendMessage(messageType string, id string, addr string, data string) string {
-	msg := &Message{
-		Type: messageType,
-		ID:   id,
-		Addr: addr,
-		Data: data,
-	}
-
-	if err := c.sendMessage(msg); err != nil {
-		return err.Error()
-	}
-	return ""
+	return c.SendMessageResult(messageType, id, addr, data).Message
 }
 
 // IsConnected returns true if currently connected
 func (c *Client) IsConnected() bool {
-	c.quicMutex.Lock()
-	defer c.quicMutex.Unlock()
-	return c.isConnected
+	return c.isConnected.Load()
+}
+
+// GetConnectionCount returns the number of connections relayed by the SDK
+func (c *Client) GetConnectionCount() int {
+	c.clientMutex.RLock()
+	defer c.clientMutex.RUnlock()
+	return len(c.clientConns)
+}
+
+// GetConnectionIDs returns the IDs of connections relayed by the SDK, comma-separated
+func (c *Client) GetConnectionIDs() string {
+	c.clientMutex.RLock()
+	ids := make([]string, 0, len(c.clientConns))
+	for id := range c.clientConns {
+		ids = append(ids, id)
+	}
+	c.clientMutex.RUnlock()
+
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
 }
 
 // connectionLoop handles automatic reconnection with exponential backoff
 func (c *Client) connectionLoop() {
-	for c.shouldRun {
+	for c.shouldRun.Load() {
+		if !c.waitWhilePaused() {
+			return
+		}
+
 		c.retryMutex.Lock()
 		attempt := c.consecutiveFailures + 1
 		c.retryMutex.Unlock()
 
-		c.log(fmt.Sprintf("Attempting to connect (attempt %d)", attempt))
+		c.logDebug(fmt.Sprintf("Attempting to connect (attempt %d)", attempt))
+		c.observe(EventConnecting, map[string]interface{}{"attempt": attempt, "server": c.currentServer()})
 
-		if c.connect() {
-			// Successfully connected
+		var cooldown time.Duration
+		var err error
+		// A connection made by ConnectOnce is adopted rather than redialed
+		adopted := c.IsConnected()
+		if !adopted {
+			err = c.connect()
+		}
+		if err == nil {
+			connectedAt := time.Now()
+			// Successfully connected; a ForceReconnect made meanwhile is served
+			c.takeImmediateReconnect()
+			if !adopted {
+				c.stats.connects.Add(1)
+				c.stats.lastConnectedMs.Store(nowMillis())
+			}
+			// The backoff is only reset once the connection proved stable
 			c.retryMutex.Lock()
-			c.consecutiveFailures = 0
+			c.lastFailureReason = ""
 			c.retryMutex.Unlock()
 
-			c.serverMutex.Lock()
-			c.currentServerIdx = 0
-			c.serverMutex.Unlock()
-
 			if c.callback != nil {
 				c.callback.OnConnected()
 			}
+			c.notifyConnectedDetails()
 			c.log("Successfully connected and authenticated")
+			c.observe(EventConnected, map[string]interface{}{"server": c.currentServer()})
+			go c.resumeImported()
 
-			// Wait for disconnection
+			// Wait for disconnection, refreshing the connection when it gets too old
+			stopLifetime := c.startLifetimeTimer()
 			c.waitForDisconnection()
+			stopLifetime()
 
-			if c.callback != nil {
-				c.callback.OnDisconnected("Connection lost")
+			c.retryMutex.Lock()
+			reason := c.reconnectReason
+			c.reconnectReason = ""
+			c.retryMutex.Unlock()
+			c.settleBackoff(time.Since(connectedAt), reason != "")
+
+			if reason != "" {
+				// Deliberate reconnect, not a flap
+				c.observe(EventDisconnected, map[string]interface{}{"reason": reason})
+				if c.callback != nil {
+					c.callback.OnDisconnected(reason)
+				}
+				c.log(fmt.Sprintf("Reconnecting: %s", reason))
+			} else {
+				c.observe(EventDisconnected, map[string]interface{}{"reason": "Connection lost"})
+				if c.callback != nil {
+					c.callback.OnDisconnected("Connection lost")
+				}
+				c.logError("Connection lost, will reconnect...")
+				cooldown = c.recordDisconnect(time.Since(connectedAt))
+				c.failbackToPrimary(time.Since(connectedAt))
 			}
-			c.log("Connection lost, will reconnect...")
 		} else {
 			// Connection failed
+			c.stats.connectFailures.Add(1)
+			reason := classifyConnectError(err)
+
 			c.retryMutex.Lock()
 			c.consecutiveFailures++
 			failures := c.consecutiveFailures
+			c.lastFailureReason = reason
 			c.retryMutex.Unlock()
 
-			// Try next server after 3 consecutive failures
-			if failures >= 3 && len(c.serverList) > 1 {
+			c.observe(EventConnectFailed, map[string]interface{}{"reason": reason, "error": err.Error()})
+
+			var authErr *authError
+			if errors.As(err, &authErr) && authErr.permanent() {
+				// The same token will be rejected again; wait for a new one
+				c.holdForNewToken(authErr)
+				continue
+			}
+			rotate := failures >= 3
+			if authErr != nil && authErr.serverSide() {
+				// The token may be fine; this server failed to accept it
+				c.quarantineServer(c.currentServer())
+				rotate = true
+			}
+
+			if isTrustFailure(reason) {
+				c.notifyError(reason, err.Error())
+				if c.callback != nil {
+					c.callback.OnDisconnected(reason)
+				}
+			}
+
+			// Try next server after 3 consecutive failures or a quarantine
+			if rotate && len(c.serverList) > 1 {
 				c.rotateServer()
 			}
 		}
 
-		// Calculate exponential backoff delay
-		if c.shouldRun {
-			delay := c.calculateRetryDelay()
-			c.log(fmt.Sprintf("Retrying in %v...", delay))
-			time.Sleep(delay)
+		// Calculate exponential backoff delay; ForceReconnect skips the
+		// backoff but not the flap cooldown
+		if c.shouldRun.Load() {
+			delay := cooldown
+			if !c.takeImmediateReconnect() {
+				delay = max(delay, c.calculateRetryDelay())
+			}
+			if !c.waitRetryDelay(delay, time.Now().Add(cooldown)) {
+				return
+			}
 		}
 	}
 }
 
-// calculateRetryDelay computes exponential backoff delay
-// 1s -> 2s -> 4s -> 8s -> 16s -> 32s -> 64s -> 120s (max)
+// waitRetryDelay waits before the next attempt, false if the client stopped
+// A wake-up ends the wait early, but one from ForceReconnect still waits
+// out the cooldown until cooldownEnd.
+func (c *Client) waitRetryDelay(delay time.Duration, cooldownEnd time.Time) bool {
+	if delay <= 0 {
+		return true
+	}
+	c.logDebug(fmt.Sprintf("Retrying in %v...", delay))
+	c.observe(EventReconnectScheduled, map[string]interface{}{"delayMs": delay.Milliseconds()})
+	c.notifyReconnectScheduled(delay)
+
+	select {
+	case <-time.After(delay):
+		return true
+	case <-c.reconnectWake():
+		if !c.takeImmediateReconnect() {
+			// Already reconnecting now, whatever woke us
+			return true
+		}
+	case <-c.ctx.Done():
+		return false
+	}
+
+	remaining := time.Until(cooldownEnd)
+	if remaining <= 0 {
+		return true
+	}
+	c.logDebug(fmt.Sprintf("Reconnecting after flap cooldown in %v...", remaining.Round(time.Millisecond)))
+	select {
+	case <-time.After(remaining):
+		return true
+	case <-c.ctx.Done():
+		return false
+	}
+}
+
+// calculateRetryDelay computes the backoff delay from the ReconnectPolicy
+// Default: 1s -> 2s -> 4s -> 8s -> 16s -> 32s -> 64s (max)
 func (c *Client) calculateRetryDelay() time.Duration {
+	cfg := c.getConfig()
+
 	c.retryMutex.Lock()
 	defer c.retryMutex.Unlock()
 
-	if c.consecutiveFailures == 0 {
-		return 1 * time.Second
+	// Retrying quickly won't help until the server's certificate is fixed
+	if c.consecutiveFailures > 0 && isTrustFailure(c.lastFailureReason) {
+		return 2 * time.Minute
 	}
 
-	// Exponential backoff: 2^(n-1) seconds
-	exponent := c.consecutiveFailures - 1
-	if exponent > 6 {
-		exponent = 6 // Cap at 2^6 = 64 seconds base
+	return backoffDelay(cfg, c.consecutiveFailures)
+}
+
+// requestReconnect drops the current connection so the loop reconnects
+// reason is reported through OnDisconnected instead of "Connection lost".
+func (c *Client) requestReconnect(reason string) {
+	if !c.IsConnected() {
+		return
 	}
 
-	delay := time.Duration(1<<uint(exponent)) * time.Second
-	maxDelay := 2 * time.Minute
+	c.retryMutex.Lock()
+	c.reconnectReason = reason
+	c.retryMutex.Unlock()
 
-	if delay > maxDelay {
-		delay = maxDelay
-	}
+	c.disconnect()
+}
+
+// currentServer returns the server the next connection attempt targets
+func (c *Client) currentServer() string {
+	c.serverMutex.Lock()
+	defer c.serverMutex.Unlock()
+	return c.serverURL
+}
 
-	return delay
+// currentMetadata returns the metadata sent during authentication
+func (c *Client) currentMetadata() string {
+	c.serverMutex.Lock()
+	defer c.serverMutex.Unlock()
+	return c.metadata
 }
 
 // rotateServer switches to the next server in the list
@@ -233,7 +479,13 @@ func (c *Client) rotateServer() {
 	defer c.serverMutex.Unlock()
 
 	oldIdx := c.currentServerIdx
-	c.currentServerIdx = (c.currentServerIdx + 1) % len(c.serverList)
+	// Skip quarantined servers unless all of them are
+	for step := 1; step <= len(c.serverList); step++ {
+		c.currentServerIdx = (oldIdx + step) % len(c.serverList)
+		if !c.isQuarantinedLocked(c.serverList[c.currentServerIdx]) {
+			break
+		}
+	}
 	c.serverURL = c.serverList[c.currentServerIdx]
 
 	c.log(fmt.Sprintf("Rotating server: %s -> %s",
@@ -246,195 +498,413 @@ func (c *Client) rotateServer() {
 }
 
 // connect establishes QUIC connection and authenticates
-func (c *Client) connect() bool {
-	serverAddr := c.serverURL
-	if !strings.Contains(serverAddr, ":") {
-		serverAddr = serverAddr + ":8443"
+// On success the read loop runs in the background until the connection drops.
+func (c *Client) connect() error {
+	return c.connectAttempt(c.ctx)
+}
+
+// connectWithContext is connect bounded by ctx
+// Canceling ctx before authentication completes tears the attempt down.
+func (c *Client) connectWithContext(ctx context.Context) error {
+	serverAddr, err := normalizeServerAddr(c.currentServer())
+	if err != nil {
+		c.logError(fmt.Sprintf("Failed to connect: %v", err))
+		return err
 	}
 
 	// Build TLS config
 	tlsConf := c.buildTLSConfig(serverAddr)
 
-	// Dial QUIC
-	conn, err := quic.DialAddr(c.ctx, serverAddr, tlsConf, nil)
+	udpAddr, err := c.resolveServerAddr(serverAddr)
 	if err != nil {
-		c.log(fmt.Sprintf("Failed to connect: %v", err))
-		return false
+		c.logError(fmt.Sprintf("Failed to resolve %s: %v", serverAddr, err))
+		return err
+	}
+
+	transport, err := c.ensureTransport()
+	if err != nil {
+		c.logError(fmt.Sprintf("Failed to connect: %v", err))
+		return err
+	}
+
+	// Dial QUIC on the shared transport
+	conn, err := c.dialQUIC(ctx, transport, udpAddr, tlsConf)
+	if err != nil {
+		c.logError(fmt.Sprintf("Failed to connect: %v", err))
+		return err
 	}
 
-	// Wait briefly for server to accept
-	time.Sleep(100 * time.Millisecond)
+	// Abandon the half-open connection if ctx ends before auth completes
+	stopAbort := context.AfterFunc(ctx, func() {
+		conn.CloseWithError(1, "connect canceled")
+	})
+	defer stopAbort()
 
-	// Open stream
-	stream, err := conn.OpenStreamSync(c.ctx)
+	// Open stream; the server sees it with the auth message, so there's
+	// nothing to wait for
+	stream, err := c.openStream(conn)
 	if err != nil {
-		c.log(fmt.Sprintf("Failed to open stream: %v", err))
+		c.logError(fmt.Sprintf("Failed to open stream: %v", err))
 		conn.CloseWithError(1, "failed to open stream")
-		return false
+		return err
 	}
 
 	c.quicMutex.Lock()
 	c.quicConn = conn
-	c.quicStream = stream
-	c.isConnected = true
+	c.isConnected.Store(true)
 	c.quicMutex.Unlock()
+	c.setControlStream(stream)
 
 	// Authenticate
-	if !c.authenticate(stream) {
-		c.log("Authentication failed")
+	decoder := c.newStreamDecoder(stream)
+	err = c.authenticate(stream, decoder)
+	if errors.Is(err, quic.Err0RTTRejected) {
+		stream, decoder, err = c.authenticateAfter0RTTRejected(ctx, conn)
+	}
+	if err != nil {
+		c.logError(fmt.Sprintf("Authentication failed: %v", err))
 		conn.CloseWithError(1, "authentication failed")
 		c.quicMutex.Lock()
-		c.isConnected = false
+		c.isConnected.Store(false)
 		c.quicMutex.Unlock()
-		return false
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
 	}
 
 	c.log("Authenticated successfully")
+	c.serverMutex.Lock()
+	c.activeServer = c.serverURL
+	c.serverMutex.Unlock()
+	c.stats.lastMessageMs.Store(nowMillis())
+	if c.hasCap(capConnStreams) {
+		go c.acceptConnStreams(conn)
+	}
 
 	// Start reading messages
-	c.readMessages(stream)
+	go c.readMessages(stream, decoder)
 
-	return true
+	return nil
 }
 
 // buildTLSConfig creates TLS configuration
 func (c *Client) buildTLSConfig(serverAddr string) *tls.Config {
+	if config := c.tlsConfigOverride(); config != nil {
+		c.log("Using TLS configuration from SetTLSConfig")
+		return config
+	}
+
+	cfg := c.getConfig()
 	config := &tls.Config{
 		NextProtos: []string{"vyx-proxy"},
-		MinVersion: tls.VersionTLS12,
+		// QUIC requires TLS 1.3
+		MinVersion:         tls.VersionTLS13,
+		ClientSessionCache: &c.sessions,
+		VerifyConnection:   cipherSuiteVerifier(cfg.TLSCipherSuites),
 	}
 
-	// Extract hostname
-	host := serverAddr
-	if strings.Contains(serverAddr, ":") {
-		host, _, _ = net.SplitHostPort(serverAddr)
-	}
+	host := serverHost(serverAddr)
 
 	// Development mode for localhost
-	if host == "localhost" || host == "127.0.0.1" {
+	if isDevelopmentHost(host) {
 		c.log("Development mode: Using InsecureSkipVerify")
 		config.InsecureSkipVerify = true
 	} else {
 		// Production mode
-		c.log(fmt.Sprintf("Production mode: Verifying TLS for %s", host))
+		c.logDebug(fmt.Sprintf("Production mode: Verifying TLS for %s", host))
 		config.ServerName = host
 		config.InsecureSkipVerify = false
 	}
 
+	if pin, _ := decodePin(cfg.PinnedCertSHA256); pin != nil {
+		c.log("Certificate pinning enabled")
+		config.VerifyPeerCertificate = pinVerifier(pin)
+	}
+
 	return config
 }
 
+// preAuthTypes are messages a server may send before the auth result
+// authenticate skips them and keeps waiting within the same timeout.
+var preAuthTypes = map[string]bool{
+	"hello":        true,
+	"server_hello": true,
+	"banner":       true,
+	"notice":       true,
+}
+
 // authenticate sends authentication to server
-func (c *Client) authenticate(stream *quic.Stream) bool {
+// Rejections by the server are returned as *authError and reported to
+// OnAuthFailed; other errors mean the stream failed.
+func (c *Client) authenticate(stream *quic.Stream, decoder *json.Decoder) error {
+	nonce, err := randomHex(16)
+	if err != nil {
+		return fmt.Errorf("failed to generate auth nonce: %w", err)
+	}
+
 	authMsg := Message{
-		Type: "auth",
-		ID:   c.apiToken,
-		Data: c.metadata,
+		Type:  "auth",
+		ID:    c.currentToken(),
+		Data:  c.currentMetadata(),
+		Nonce: nonce,
+		Caps:  c.localCaps(),
+		Proto: protocolVersion,
 	}
 
-	c.log("Sending authentication...")
+	c.logDebug("Sending authentication...")
 	encoder := json.NewEncoder(stream)
 	if err := encoder.Encode(authMsg); err != nil {
-		c.log(fmt.Sprintf("Failed to send auth: %v", err))
-		return false
+		return fmt.Errorf("failed to send auth: %w", err)
 	}
 
-	// Wait for response with timeout
+	c.notifyAuthenticating()
+
+	// Read responses one at a time until a definitive result; the decoder
+	// is handed to the read loop afterwards, so nothing buffered is lost
 	responseChan := make(chan Message, 1)
 	errorChan := make(chan error, 1)
+	readNext := func() {
+		go func() {
+			var response Message
+			if err := decoder.Decode(&response); err != nil {
+				errorChan <- err
+				return
+			}
+			responseChan <- response
+		}()
+	}
 
-	go func() {
-		decoder := json.NewDecoder(stream)
-		var response Message
-		if err := decoder.Decode(&response); err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- response
-	}()
+	authTimeout := time.Duration(c.getConfig().AuthTimeoutMs) * time.Millisecond
+	timeout := time.NewTimer(authTimeout)
+	defer timeout.Stop()
 
-	select {
-	case response := <-responseChan:
-		c.log(fmt.Sprintf("Auth response: %s", response.Type))
-		if response.Type == "auth_success" {
-			// Notify Android
-			if c.callback != nil {
-				c.callback.OnMessage("auth_success", response.ID, "", response.Data)
+	readNext()
+	for {
+		select {
+		case response := <-responseChan:
+			c.logDebug(fmt.Sprintf("Auth response: %s", response.Type))
+			if preAuthTypes[response.Type] {
+				// Preamble such as a server hello or banner, not an auth result
+				readNext()
+				continue
 			}
-			return true
-		}
-		if response.Type == "error" {
-			if c.callback != nil {
-				c.callback.OnMessage("error", response.ID, "", response.Data)
+			// Servers that don't support nonces leave it empty; anything else must match
+			if response.Nonce != "" && response.Nonce != nonce {
+				return c.authFailed(&authError{reason: AuthFailureProtocolError, message: "auth response nonce mismatch"})
 			}
-			return false
+			switch response.Type {
+			case "auth_success":
+				c.setServerProtocol(response.Proto)
+				c.setNegotiatedCaps(authMsg.Caps, response.Caps)
+				// Notify Android
+				if c.callback != nil {
+					c.callback.OnMessage("auth_success", response.ID, "", response.Data)
+				}
+				return nil
+			case "error":
+				if c.callback != nil {
+					c.callback.OnMessage("error", response.ID, "", response.Data)
+				}
+				return c.authFailed(parseAuthFailure(response.Data))
+			case "auth_progress", "challenge":
+				// Multi-step auth: surface the step and give it a fresh timeout
+				stage := response.ID
+				if stage == "" {
+					stage = response.Type
+				}
+				c.notifyAuthProgress(stage, response.Data)
+				timeout.Reset(authTimeout)
+				readNext()
+			default:
+				return c.authFailed(&authError{reason: AuthFailureProtocolError, message: "unexpected message " + response.Type})
+			}
+		case err := <-errorChan:
+			if isDecodeError(err) {
+				return c.authFailed(&authError{reason: AuthFailureProtocolError, message: fmt.Sprintf("malformed auth response: %v", err)})
+			}
+			// The stream or connection failed; that's a network problem
+			return fmt.Errorf("auth response error: %w", err)
+		case <-timeout.C:
+			return c.authFailed(&authError{reason: AuthFailureTimeout, message: fmt.Sprintf("no response within %v", authTimeout)})
 		}
-		return false
-	case err := <-errorChan:
-		c.log(fmt.Sprintf("Auth response error: %v", err))
-		return false
-	case <-time.After(10 * time.Second):
-		c.log("Authentication timeout")
-		return false
 	}
 }
 
+// authFailed reports an authentication rejection and returns it
+func (c *Client) authFailed(err *authError) error {
+	c.notifyAuthFailed(err.reason)
+	return err
+}
+
 // readMessages reads messages from QUIC stream
-func (c *Client) readMessages(stream *quic.Stream) {
-	decoder := json.NewDecoder(stream)
+func (c *Client) readMessages(stream *quic.Stream, decoder *json.Decoder) {
+	reader := c.streamReader(stream, decoder)
+	inbound := c.startInbound()
+	for {
+		err := c.readStream(reader, inbound)
+		if err == nil {
+			inbound.stop()
+			return
+		}
 
-	for c.shouldRun {
-		var msg Message
-		err := decoder.Decode(&msg)
-		if err != nil {
-			c.log(fmt.Sprintf("Read error: %v", err))
-
-			// Close all client connections
-			c.clientMutex.Lock()
-			for id, cc := range c.clientConns {
-				cc.conn.Close()
-				close(cc.dataChan)
-				delete(c.clientConns, id)
+		// The server closed only the control stream; keep the QUIC connection
+		if errors.Is(err, io.EOF) && c.getConfig().ReopenControlStream {
+			// Finish the old stream's messages before its connections go
+			inbound.stop()
+			inbound = c.startInbound()
+			c.closeAllConnections()
+			if newStream, newDecoder, reopenErr := c.reopenControlStream(); reopenErr == nil {
+				reader = c.streamReader(newStream, newDecoder)
+				continue
+			} else {
+				c.logError(fmt.Sprintf("Failed to reopen control stream: %v", reopenErr))
 			}
-			c.clientMutex.Unlock()
+		}
 
-			c.quicMutex.Lock()
-			c.isConnected = false
-			c.quicMutex.Unlock()
+		c.logError(fmt.Sprintf("Read error: %v", err))
+		c.flushSampledLogs()
+		inbound.stop()
+		c.notifyTransportError(err)
 
-			return
+		// Close all client connections
+		c.closeAllConnections()
+		c.clearOpen()
+		c.clearConnStreams()
+		c.clearNegotiatedCaps()
+
+		c.quicMutex.Lock()
+		c.isConnected.Store(false)
+		c.quicMutex.Unlock()
+
+		return
+	}
+}
+
+// readStream decodes messages and queues them for handling until the stream fails
+// Returns nil if the client was stopped.
+func (c *Client) readStream(reader messageReader, inbound *inboundQueue) error {
+	for c.shouldRun.Load() {
+		msg := &Message{}
+		if err := reader.next(msg); err != nil {
+			return err
 		}
+		c.accountMessage(msg)
+		inbound.messages <- msg
+	}
+	return nil
+}
+
+// receiveMessage accounts for and handles a message read from any stream
+func (c *Client) receiveMessage(msg *Message) {
+	c.accountMessage(msg)
+	c.processMessage(msg)
+}
+
+// accountMessage records a message as it is read
+func (c *Client) accountMessage(msg *Message) {
+	c.stats.messagesReceived.Add(1)
+	c.stats.lastMessageMs.Store(nowMillis())
+	c.recordDownlink(msg.TS)
+	c.logSampled("recv:"+msg.Type, fmt.Sprintf("Received: %s", msg.Type))
+	c.observe(EventMessageReceived, map[string]interface{}{"type": msg.Type, "id": msg.ID})
+}
 
-		c.log(fmt.Sprintf("Received: %s", msg.Type))
-		c.handleMessage(&msg)
+// processMessage resolves a pending request or handles the message
+func (c *Client) processMessage(msg *Message) {
+	if c.resolvePending(msg) {
+		return
 	}
+	c.handleMessage(msg)
+}
+
+// setControlStream installs the stream used by sendMessage
+func (c *Client) setControlStream(stream *quic.Stream) {
+	delay := time.Duration(c.getConfig().WriteCoalesceDelayMs) * time.Millisecond
+
+	c.quicMutex.Lock()
+	c.quicStream = stream
+	c.streamWriter = newCoalescingWriter(stream, delay, c.writeTimeout())
+	c.quicMutex.Unlock()
+}
+
+// newStreamDecoder creates the message decoder for a control stream
+// One decoder must be used for the stream's whole life since it reads ahead.
+func (c *Client) newStreamDecoder(stream *quic.Stream) *json.Decoder {
+	return json.NewDecoder(&countingReader{r: stream, n: &c.stats.bytesReceived})
 }
 
 // handleMessage processes incoming messages
 func (c *Client) handleMessage(msg *Message) {
+	if c.deliverInternal(msg) {
+		return
+	}
+
 	if c.callback == nil {
 		return
 	}
 
 	switch msg.Type {
 	case "connect":
+		c.unmarkClosed(msg.ID)
+		c.stats.connectionsOpened.Add(1)
+		c.trackOpen(msg.ID, msg.Addr)
+		c.observe(EventConnectionOpened, map[string]interface{}{"id": msg.ID, "addr": msg.Addr, "origin": "server"})
+		if c.stopping.Load() {
+			c.refuseConnection(msg.ID, msg.Addr, refusedShuttingDown)
+			return
+		}
+		if !c.breakerAllow(msg.Addr) {
+			c.refuseConnection(msg.ID, msg.Addr, refusedCircuitOpen)
+			return
+		}
+		if c.getConfig().LocalDialing {
+			if reason := c.checkConnectPolicy(msg.Addr); reason != "" {
+				c.refuseConnection(msg.ID, msg.Addr, reason)
+				return
+			}
+			go c.handleLocalConnect(msg.ID, msg.Addr, msg.Priority)
+			return
+		}
 		// Forward to Android to handle the TCP connection
-		c.callback.OnMessage("connect", msg.ID, msg.Addr, msg.Data)
+		// addr may hold several comma-separated candidates to try in order
+		c.trackPendingConnect(msg.ID, msg.Addr)
+		c.dispatchMessage("connect", msg.ID, msg.Addr, msg.Data)
 
 	case "data":
+		if msg.raw != nil {
+			c.handleBinaryData(msg)
+			return
+		}
+		data, err := c.openData(msg.ID, msg.Data)
+		if err != nil {
+			c.log(fmt.Sprintf("Dropping connection %s: %v", msg.ID, err))
+			c.notifyError(reasonDecryptFailed, err.Error())
+			c.closeWithReason(msg.ID, reasonDecryptFailed)
+			return
+		}
+		if data, err = decompressData(msg.Enc, data); err != nil {
+			c.log(fmt.Sprintf("Dropping connection %s: %v", msg.ID, err))
+			c.notifyError(reasonDecompressFailed, err.Error())
+			c.closeWithReason(msg.ID, reasonDecompressFailed)
+			return
+		}
+		if c.deliverData(msg.ID, data) {
+			return
+		}
 		// Forward data to existing connection
-		c.callback.OnMessage("data", msg.ID, "", msg.Data)
+		c.dispatchMessage("data", msg.ID, "", data)
 
 	case "close":
 		// Close connection
-		c.clientMutex.Lock()
-		if cc, ok := c.clientConns[msg.ID]; ok {
-			cc.conn.Close()
-			close(cc.dataChan)
-			delete(c.clientConns, msg.ID)
+		c.closeConnection(msg.ID, false)
+		c.connectionEnded(msg.ID)
+		if !c.markClosed(msg.ID) {
+			// Already closed locally or by an earlier "close"
+			c.logDebug(fmt.Sprintf("Ignoring duplicate close for %s", msg.ID))
+			return
 		}
-		c.clientMutex.Unlock()
-		c.callback.OnMessage("close", msg.ID, "", "")
+		c.dispatchMessage("close", msg.ID, "", "")
 
 	case "ping":
 		// Respond with pong
@@ -444,105 +914,346 @@ func (c *Client) handleMessage(msg *Message) {
 		})
 
 	case "error":
-		c.callback.OnMessage("error", msg.ID, "", msg.Data)
+		if msg.ID != "" {
+			c.dispatchMessage("error", msg.ID, "", msg.Data)
+			return
+		}
+		// Connection-less errors may repeat in storms; coalesce them
+		data := msg.Data
+		c.limitError("message\x00"+data, func(repeats int) {
+			c.dispatchMessage("error", "", "", withRepeats(data, repeats))
+		})
+
+	case "slow_down":
+		c.handleSlowDown(msg.Data)
+
+	case "resume":
+		c.resumeEstablishment("server resumed")
+
+	case "redirect":
+		go c.handleRedirect(msg.Addr)
 
 	default:
-		c.log(fmt.Sprintf("Unknown message type: %s", msg.Type))
+		c.logDebug(fmt.Sprintf("Unknown message type: %s", msg.Type))
 	}
 }
 
 // sendMessage sends a message to server
+// Writes are scheduled by priority, see sendScheduler.
 func (c *Client) sendMessage(msg *Message) error {
-	c.quicMutex.Lock()
-	defer c.quicMutex.Unlock()
+	if err := c.checkMessageSupported(msg.Type); err != nil {
+		return &sendError{code: SendErrUnsupported, err: err}
+	}
+	if msg.Type == "data" {
+		frame, ok, err := c.binaryDataFrame(msg)
+		if err != nil {
+			return &sendError{code: SendErrInvalidData, err: err}
+		}
+		if ok {
+			return c.writeEncoded(msg, frame)
+		}
 
-	if c.quicStream == nil {
-		return fmt.Errorf("no active QUIC stream")
+		// Callers may resend msg, so don't encode or encrypt it in place
+		encoded := *msg
+		if msg.raw != nil {
+			if compressed, ok := c.compressPayload(msg.raw); ok {
+				encoded.Data = base64.StdEncoding.EncodeToString(compressed)
+				encoded.Enc = encDeflate
+			} else {
+				encoded.Data = base64.StdEncoding.EncodeToString(msg.raw)
+			}
+			encoded.raw = nil
+		} else if compressed, ok := c.compressData(encoded.Data); ok {
+			encoded.Data = compressed
+			encoded.Enc = encDeflate
+		}
+		sealed, err := c.sealData(encoded.ID, encoded.Data)
+		if err != nil {
+			return &sendError{code: SendErrInvalidData, err: err}
+		}
+		encoded.Data = sealed
+		msg = &encoded
+	}
+
+	if c.getConfig().MessageTimestamps {
+		msg.TS = nowMillis()
 	}
 
 	data, err := json.Marshal(msg)
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return &sendError{code: SendErrMarshal, err: fmt.Errorf("failed to marshal message: %w", err)}
 	}
 	data = append(data, '\n')
+	return c.writeEncoded(msg, data)
+}
 
-	_, err = c.quicStream.Write(data)
-	if err != nil {
-		return fmt.Errorf("failed to write to stream: %w", err)
+// writeEncoded writes an encoded message and accounts for it
+func (c *Client) writeEncoded(msg *Message, data []byte) error {
+	if err := c.writeMessage(msg, data); err != nil {
+		if errors.Is(err, errNoStream) {
+			return &sendError{code: SendErrNoStream, err: err}
+		}
+		if errors.Is(err, errWriteTimeout) {
+			return &sendError{code: SendErrTimeout, err: err}
+		}
+		return &sendError{code: SendErrWrite, err: err}
+	}
+	c.stats.bytesSent.Add(int64(len(data)))
+	c.stats.messagesSent.Add(1)
+	c.observe(EventMessageSent, map[string]interface{}{"type": msg.Type, "id": msg.ID, "bytes": len(data)})
+
+	return nil
+}
+
+// writeMessage writes an encoded message to its connection's stream, or
+// to the control stream
+func (c *Client) writeMessage(msg *Message, data []byte) error {
+	if cs := c.connStreamFor(msg); cs != nil {
+		if err := cs.write(data, c.writeTimeout()); err != nil {
+			if errors.Is(err, errWriteTimeout) {
+				c.connStreamWriteTimedOut(msg.ID)
+			}
+			return fmt.Errorf("failed to write to connection stream: %w", err)
+		}
+		return nil
 	}
 
+	priority := c.messagePriority(msg)
+	c.sendSched.acquire(priority)
+	defer c.sendSched.release()
+
+	c.quicMutex.Lock()
+	writer := c.streamWriter
+	c.quicMutex.Unlock()
+
+	if writer == nil {
+		return errNoStream
+	}
+
+	if err := writer.write(data, isUrgent(msg, priority)); err != nil {
+		if errors.Is(err, errWriteTimeout) {
+			c.controlWriteTimedOut(writer)
+		}
+		return fmt.Errorf("failed to write to stream: %w", err)
+	}
 	return nil
 }
 
 // RegisterConnection registers a TCP connection (called from Android after successful TCP connect)
 // Note: This method is not exported for Go Mobile (uses net.Conn which can't be bound)
 func (c *Client) registerConnection(id string, conn net.Conn) {
+	ctx, cancel := context.WithCancel(c.ctx)
 	dataChan := make(chan []byte, 10000)
-	cc := &Connection{conn: conn, dataChan: dataChan}
+	cfg := c.getConfig()
+	cc := &Connection{
+		conn:     conn,
+		dataChan: dataChan,
+		ctx:      ctx,
+		cancel:   cancel,
+		limiter:  newTokenBucket(cfg.ConnRateLimitBytesPerSec, cfg.ConnRateLimitBurstBytes),
+
+		idleTimeout: time.Duration(cfg.ConnIdleTimeoutMs) * time.Millisecond,
+		fin:         halfClose{peerFIN: make(chan struct{})},
+	}
+	cc.touch()
 
 	c.clientMutex.Lock()
 	c.clientConns[id] = cc
 	c.clientMutex.Unlock()
 
+	c.startTTL(id, time.Duration(cfg.DefaultConnectionTTLMs)*time.Millisecond)
+
+	// Unblock the reader as soon as the connection is canceled
+	cc.stopAutoClose = context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+
+	if cfg.RelayMode == RelayModePool {
+		c.addToRelayPool(cc, id)
+		return
+	}
+
 	// Start relay goroutines
+	cc.relays.Add(2)
 	go c.relayFromConnToQuic(cc, id)
 	go c.relayFromChanToConn(cc, id)
 }
 
 // relayFromConnToQuic reads from TCP connection and sends to QUIC
 func (c *Client) relayFromConnToQuic(cc *Connection, id string) {
-	buffer := make([]byte, 32768)
+	defer cc.relays.Done()
+	pooled := c.getRelayBuffer()
+	defer c.putRelayBuffer(pooled)
+	buffer := *pooled
 	for {
+		if cc.idleTimeout > 0 {
+			cc.conn.SetReadDeadline(cc.idleDeadline())
+		}
 		n, err := cc.conn.Read(buffer)
 		if err != nil {
-			c.sendMessage(&Message{Type: "close", ID: id})
-			c.clientMutex.Lock()
-			if _, ok := c.clientConns[id]; ok {
-				cc.conn.Close()
-				close(cc.dataChan)
-				delete(c.clientConns, id)
+			if isReadTimeout(err) && cc.ctx.Err() == nil && cc.idleTimeout > 0 {
+				if cc.idleExpired() {
+					c.closeIdle(cc, id)
+					return
+				}
+				// Data flowed the other way meanwhile
+				continue
 			}
-			c.clientMutex.Unlock()
+			if errors.Is(err, io.EOF) && cc.ctx.Err() == nil && c.halfCloseFromTarget(cc, id) {
+				return
+			}
+			c.closeConnection(id, true)
 			return
 		}
 
 		if n > 0 {
-			encoded := base64.StdEncoding.EncodeToString(buffer[:n])
-			c.sendMessage(&Message{
-				Type: "data",
-				ID:   id,
-				Data: encoded,
-			})
+			cc.touch()
+			if c.throttle(cc, n) != nil {
+				return
+			}
+			start := c.histogramClock()
+			err := c.sendData(c.dataMessage(id, buffer[:n]))
+			if err != nil {
+				// The data is lost, so the connection can't continue
+				c.log(fmt.Sprintf("Failed to relay data for %s: %v", id, err))
+				c.closeConnection(id, true)
+				return
+			}
+			c.recordUplinkSend(start, n)
+			c.countOut(cc, n)
 		}
 	}
 }
 
 // relayFromChanToConn reads from channel and writes to TCP connection
 func (c *Client) relayFromChanToConn(cc *Connection, id string) {
-	for data := range cc.dataChan {
-		_, err := cc.conn.Write(data)
-		if err != nil {
-			c.sendMessage(&Message{Type: "close", ID: id})
-			c.clientMutex.Lock()
-			if _, ok := c.clientConns[id]; ok {
-				cc.conn.Close()
-				close(cc.dataChan)
-				delete(c.clientConns, id)
+	defer cc.relays.Done()
+	for {
+		select {
+		case <-cc.ctx.Done():
+			return
+		case data, ok := <-cc.dataChan:
+			if !ok || !c.writeToTarget(cc, id, data) {
+				return
+			}
+		case <-cc.fin.peerFIN:
+			// Everything the server sent before its FIN is queued already
+			for {
+				select {
+				case data, ok := <-cc.dataChan:
+					if !ok || !c.writeToTarget(cc, id, data) {
+						return
+					}
+					continue
+				default:
+				}
+				break
 			}
-			c.clientMutex.Unlock()
+			c.halfCloseTarget(cc, id)
 			return
 		}
 	}
 }
 
+// writeToTarget writes data from the server to the target
+// Returns false if the connection can't continue.
+func (c *Client) writeToTarget(cc *Connection, id string, data []byte) bool {
+	if c.throttle(cc, len(data)) != nil {
+		return false
+	}
+	start := c.histogramClock()
+	if err := writeFull(cc.conn, data); err != nil {
+		c.closeConnection(id, true)
+		return false
+	}
+	c.recordDownlinkWrite(start, len(data))
+	c.countIn(cc, len(data))
+	cc.touch()
+	return true
+}
+
+// CancelConnection cancels a registered connection's context
+// Both relay goroutines exit promptly and "close" is sent to the server.
+// Returns error message or empty string on success
+func (c *Client) CancelConnection(id string) string {
+	if !c.closeConnection(id, true) {
+		return fmt.Sprintf("unknown connection: %s", id)
+	}
+	return ""
+}
+
+// CloseConnection closes one tunneled connection, e.g. when the user cancels a download
+// Connections relayed by the SDK have their socket closed; for ones the app
+// handles, the app closes its own socket. Either way the server gets a
+// "close" and other connections are left alone.
+// Returns error message or empty string on success
+func (c *Client) CloseConnection(id string) string {
+	if c.closeConnection(id, true) {
+		return ""
+	}
+	if !c.isOpen(id) {
+		return fmt.Sprintf("unknown connection: %s", id)
+	}
+	return c.SendMessageResult("close", id, "", "").Message
+}
+
+// closeConnection removes a connection, cancels its context and releases its resources
+// Returns false if the connection was already gone, in which case nothing is sent.
+func (c *Client) closeConnection(id string, notifyServer bool) bool {
+	c.clientMutex.Lock()
+	cc, ok := c.clientConns[id]
+	if ok {
+		delete(c.clientConns, id)
+		cc.cancel()
+		cc.conn.Close()
+		close(cc.dataChan)
+	}
+	c.clientMutex.Unlock()
+	if ok {
+		c.keepMeter(id, cc)
+	}
+
+	if ok && notifyServer {
+		c.sendMessage(&Message{Type: "close", ID: id})
+		c.markClosed(id)
+	}
+	c.connectionEnded(id)
+	return ok
+}
+
+// closeAllConnections tears down every registered connection without notifying the server
+func (c *Client) closeAllConnections() {
+	c.clientMutex.Lock()
+	ids := make([]string, 0, len(c.clientConns))
+	ended := make(map[string]*Connection, len(c.clientConns))
+	for id, cc := range c.clientConns {
+		cc.cancel()
+		cc.conn.Close()
+		close(cc.dataChan)
+		delete(c.clientConns, id)
+		ids = append(ids, id)
+		ended[id] = cc
+	}
+	c.clientMutex.Unlock()
+
+	for id, cc := range ended {
+		c.keepMeter(id, cc)
+	}
+
+	for _, id := range ids {
+		c.connectionEnded(id)
+	}
+}
+
 // disconnect closes the QUIC connection
 func (c *Client) disconnect() {
 	c.quicMutex.Lock()
 	defer c.quicMutex.Unlock()
 
-	if c.quicConn != nil {
-		c.quicConn.CloseWithError(0, "client stopped")
-		c.quicConn = nil
+	// Flush coalesced writes (closes, pongs) while the stream still exists
+	if c.streamWriter != nil {
+		c.streamWriter.flush()
+		c.streamWriter = nil
 	}
 
 	if c.quicStream != nil {
@@ -550,29 +1261,36 @@ func (c *Client) disconnect() {
 		c.quicStream = nil
 	}
 
-	c.isConnected = false
+	if c.quicConn != nil {
+		c.quicConn.CloseWithError(0, "client stopped")
+		c.quicConn = nil
+	}
+
+	c.isConnected.Store(false)
+	c.clearNegotiatedCaps()
+	c.clearOpen()
+	c.clearConnStreams()
 
 	// Close all client connections
-	c.clientMutex.Lock()
-	for id, cc := range c.clientConns {
-		cc.conn.Close()
-		close(cc.dataChan)
-		delete(c.clientConns, id)
-	}
-	c.clientMutex.Unlock()
+	c.closeAllConnections()
 }
 
 // waitForDisconnection blocks until disconnected
 func (c *Client) waitForDisconnection() {
-	for c.isConnected && c.shouldRun {
-		time.Sleep(1 * time.Second)
+	for c.isConnected.Load() && c.shouldRun.Load() {
+		select {
+		case <-time.After(1 * time.Second):
+		case <-c.ctx.Done():
+			return
+		}
 	}
 }
 
-// log sends log message to Android
-func (c *Client) log(message string) {
-	log.Println(message)
-	if c.callback != nil {
-		c.callback.OnLog(message)
+// randomHex returns n random bytes encoded as hex
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
+	return hex.EncodeToString(b), nil
 }
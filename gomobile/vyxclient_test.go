@@ -0,0 +1,150 @@
+package vyxclient
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testCallback is a Callback that records log lines
+type testCallback struct {
+	logs  atomic.Int64
+	mu    sync.Mutex
+	lines []string
+}
+
+func (cb *testCallback) OnConnected()                         {}
+func (cb *testCallback) OnDisconnected(reason string)         {}
+func (cb *testCallback) OnMessage(typ, id, addr, data string) {}
+func (cb *testCallback) OnLog(message string) {
+	cb.logs.Add(1)
+	cb.mu.Lock()
+	cb.lines = append(cb.lines, message)
+	cb.mu.Unlock()
+}
+
+// logged returns the log lines containing substr
+func (cb *testCallback) logged(substr string) []string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	var matches []string
+	for _, line := range cb.lines {
+		if strings.Contains(line, substr) {
+			matches = append(matches, line)
+		}
+	}
+	return matches
+}
+
+// newTestClient returns a client for an address nothing listens on, retrying fast
+func newTestClient(t *testing.T) (*Client, *testCallback) {
+	t.Helper()
+	cb := &testCallback{}
+	c := NewClient("127.0.0.1:1", "test-token", "test", "{}", cb)
+	c.SetSystemLog(false)
+	c.SetConnectTimeout(50)
+	if msg := c.SetReconnectPolicy(&ReconnectPolicy{InitialDelayMs: 1, MaxDelayMs: 5, Multiplier: 1}); msg != "" {
+		t.Fatalf("SetReconnectPolicy: %s", msg)
+	}
+	return c, cb
+}
+
+// TestStopConcurrentWithLoops runs Stop while the loops and API callers are busy
+// Meant for go test -race: shouldRun is read by every loop while Stop writes it.
+func TestStopConcurrentWithLoops(t *testing.T) {
+	c, cb := newTestClient(t)
+	c.Start()
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				c.IsConnected()
+				c.GetStats()
+				c.ForceReconnect()
+			}
+		}()
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	stopped := make(chan struct{})
+	go func() {
+		c.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+	close(done)
+	wg.Wait()
+
+	if c.shouldRun.Load() {
+		t.Fatal("shouldRun still set after Stop")
+	}
+
+	// The loops must have noticed: no more attempts, so no more logging
+	time.Sleep(100 * time.Millisecond)
+	before := c.stats.connectFailures.Load()
+	logs := cb.logs.Load()
+	time.Sleep(200 * time.Millisecond)
+	if after := c.stats.connectFailures.Load(); after != before {
+		t.Fatalf("connection loop still running after Stop: %d -> %d failures", before, after)
+	}
+	if after := cb.logs.Load(); after != logs {
+		t.Fatalf("client still logging after Stop: %d -> %d lines", logs, after)
+	}
+}
+
+// TestWaitForDisconnectionSeesConcurrentWrites flips isConnected while
+// waitForDisconnection and other readers poll it (run with -race)
+func TestWaitForDisconnectionSeesConcurrentWrites(t *testing.T) {
+	c, _ := newTestClient(t)
+	defer c.Stop()
+	c.isConnected.Store(true)
+
+	returned := make(chan struct{})
+	go func() {
+		c.waitForDisconnection()
+		close(returned)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.isConnected.Store(true)
+				c.IsConnected()
+				c.GetStats()
+			}
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case <-returned:
+		t.Fatal("waitForDisconnection returned while connected")
+	default:
+	}
+
+	c.isConnected.Store(false)
+	select {
+	case <-returned:
+	case <-time.After(3 * time.Second):
+		t.Fatal("waitForDisconnection missed the disconnect")
+	}
+}
@@ -0,0 +1,275 @@
+package vyxclient
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxWarmPerDestination caps the warm connections kept for one destination
+const maxWarmPerDestination = 8
+
+// warmConn is an idle pre-established connection
+type warmConn struct {
+	id       string
+	openedAt time.Time
+}
+
+// warmPool holds idle connections opened ahead of time, keyed by addr
+type warmPool struct {
+	mu    sync.Mutex
+	idle  map[string][]warmConn
+	addrs map[string]string // id -> addr
+
+	// refillMu serializes refills so concurrent ones don't overfill a pool
+	refillMu sync.Mutex
+}
+
+// SetWarmPool keeps size idle connections to addr open ahead of time
+// AcquireConnection(addr) then hands out a warm connection instead of paying
+// connection setup latency. size 0 removes the destination and closes its
+// warm connections. Each warm connection holds a connection open on the
+// server and at the destination, plus a connection ID and buffers here, so
+// keep pools small and only for destinations used often. Best suited to
+// protocols where the client speaks first: data the destination sends before
+// the connection is acquired is delivered to OnMessage like any other.
+// Returns error message or empty string on success
+func (c *Client) SetWarmPool(addr string, size int) string {
+	if addr == "" {
+		return "addr is required"
+	}
+	if size < 0 || size > maxWarmPerDestination {
+		return fmt.Sprintf("warm pool size must be between 0 and %d", maxWarmPerDestination)
+	}
+
+	c.updateConfig(func(cfg *clientConfig) {
+		pool := make(map[string]int, len(cfg.WarmPool)+1)
+		for a, n := range cfg.WarmPool {
+			pool[a] = n
+		}
+		if size == 0 {
+			delete(pool, addr)
+		} else {
+			pool[addr] = size
+		}
+		cfg.WarmPool = pool
+	})
+
+	if size == 0 {
+		c.drainWarm(addr, 0)
+	}
+	return ""
+}
+
+// SetWarmPoolIdleTimeout closes warm connections unused for idleMillis
+// They are replaced with fresh ones, so stale connections the destination
+// may have silently dropped aren't handed out. 0 disables eviction.
+func (c *Client) SetWarmPoolIdleTimeout(idleMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.WarmPoolIdleTimeoutMs = idleMillis
+	})
+}
+
+// AcquireConnection returns a connection to addr, warm if one is available
+// Falls back to OpenConnection when the pool for addr is empty.
+func (c *Client) AcquireConnection(addr string, priority int) (string, error) {
+	if priority == 0 {
+		priority = PriorityInteractive
+	}
+	if priority != PriorityInteractive && priority != PriorityBulk {
+		return "", fmt.Errorf("invalid priority: %d", priority)
+	}
+
+	if c.stopping.Load() {
+		return "", errShuttingDown
+	}
+
+	if id, ok := c.takeWarm(addr); ok {
+		c.setConnectionPriority(id, priority)
+		c.logDebug(fmt.Sprintf("Using warm connection %s to %s", id, addr))
+		go c.refillWarmPool()
+		return id, nil
+	}
+	return c.OpenConnection(addr, priority)
+}
+
+// warmPoolLoop keeps the configured pools filled and evicts idle connections
+func (c *Client) warmPoolLoop() {
+	for c.shouldRun.Load() {
+		if c.IsConnected() {
+			c.evictIdleWarm()
+			c.refillWarmPool()
+		} else {
+			// Connection IDs don't survive a reconnect
+			c.dropAllWarm()
+		}
+
+		select {
+		case <-time.After(time.Second):
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// refillWarmPool opens connections until every pool is at its size
+func (c *Client) refillWarmPool() {
+	c.warm.refillMu.Lock()
+	defer c.warm.refillMu.Unlock()
+
+	for addr, size := range c.getConfig().WarmPool {
+		missing := size - c.warmCount(addr)
+		for i := 0; i < missing; i++ {
+			if !c.IsConnected() || c.stopping.Load() {
+				return
+			}
+			id, err := c.OpenConnection(addr, PriorityInteractive)
+			if err != nil {
+				c.log(fmt.Sprintf("Failed to open warm connection to %s: %v", addr, err))
+				break
+			}
+			c.addWarm(addr, id)
+		}
+	}
+
+	// Close connections beyond a pool's (possibly reduced) size
+	c.warm.mu.Lock()
+	addrs := make([]string, 0, len(c.warm.idle))
+	for addr := range c.warm.idle {
+		addrs = append(addrs, addr)
+	}
+	c.warm.mu.Unlock()
+
+	pool := c.getConfig().WarmPool
+	for _, addr := range addrs {
+		c.drainWarm(addr, pool[addr])
+	}
+}
+
+// evictIdleWarm closes warm connections idle longer than the timeout
+func (c *Client) evictIdleWarm() {
+	timeout := time.Duration(c.getConfig().WarmPoolIdleTimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		return
+	}
+
+	var expired []string
+	c.warm.mu.Lock()
+	for addr, conns := range c.warm.idle {
+		kept := conns[:0]
+		for _, wc := range conns {
+			if time.Since(wc.openedAt) > timeout {
+				expired = append(expired, wc.id)
+				delete(c.warm.addrs, wc.id)
+			} else {
+				kept = append(kept, wc)
+			}
+		}
+		c.warm.idle[addr] = kept
+	}
+	c.warm.mu.Unlock()
+
+	for _, id := range expired {
+		c.closeWarm(id)
+	}
+}
+
+// drainWarm closes warm connections to addr beyond keep
+func (c *Client) drainWarm(addr string, keep int) {
+	var excess []string
+	c.warm.mu.Lock()
+	conns := c.warm.idle[addr]
+	for len(conns) > keep {
+		wc := conns[len(conns)-1]
+		conns = conns[:len(conns)-1]
+		delete(c.warm.addrs, wc.id)
+		excess = append(excess, wc.id)
+	}
+	if len(conns) == 0 {
+		delete(c.warm.idle, addr)
+	} else {
+		c.warm.idle[addr] = conns
+	}
+	c.warm.mu.Unlock()
+
+	for _, id := range excess {
+		c.closeWarm(id)
+	}
+}
+
+// closeWarm closes an unused warm connection on the server
+func (c *Client) closeWarm(id string) {
+	c.sendMessage(&Message{Type: "close", ID: id})
+	c.markClosed(id)
+	c.connectionEnded(id)
+}
+
+// addWarm adds an opened connection to the pool of addr
+func (c *Client) addWarm(addr string, id string) {
+	c.warm.mu.Lock()
+	defer c.warm.mu.Unlock()
+
+	if c.warm.idle == nil {
+		c.warm.idle = make(map[string][]warmConn)
+		c.warm.addrs = make(map[string]string)
+	}
+	c.warm.idle[addr] = append(c.warm.idle[addr], warmConn{id: id, openedAt: time.Now()})
+	c.warm.addrs[id] = addr
+}
+
+// takeWarm removes and returns the oldest warm connection to addr
+func (c *Client) takeWarm(addr string) (string, bool) {
+	c.warm.mu.Lock()
+	defer c.warm.mu.Unlock()
+
+	conns := c.warm.idle[addr]
+	if len(conns) == 0 {
+		return "", false
+	}
+	wc := conns[0]
+	c.warm.idle[addr] = conns[1:]
+	delete(c.warm.addrs, wc.id)
+	return wc.id, true
+}
+
+// warmCount returns the number of warm connections to addr
+func (c *Client) warmCount(addr string) int {
+	c.warm.mu.Lock()
+	defer c.warm.mu.Unlock()
+	return len(c.warm.idle[addr])
+}
+
+// forgetWarm drops a connection closed while it sat in the pool
+func (c *Client) forgetWarm(id string) {
+	c.warm.mu.Lock()
+	defer c.warm.mu.Unlock()
+
+	addr, ok := c.warm.addrs[id]
+	if !ok {
+		return
+	}
+	delete(c.warm.addrs, id)
+
+	conns := c.warm.idle[addr]
+	for i, wc := range conns {
+		if wc.id == id {
+			c.warm.idle[addr] = append(conns[:i], conns[i+1:]...)
+			break
+		}
+	}
+}
+
+// dropAllWarm forgets every warm connection
+func (c *Client) dropAllWarm() {
+	c.warm.mu.Lock()
+	defer c.warm.mu.Unlock()
+	c.warm.idle = nil
+	c.warm.addrs = nil
+}
+
+// warmTotal returns the number of warm connections across all pools
+func (c *Client) warmTotal() int {
+	c.warm.mu.Lock()
+	defer c.warm.mu.Unlock()
+	return len(c.warm.addrs)
+}
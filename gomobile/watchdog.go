@@ -0,0 +1,81 @@
+package vyxclient
+
+import (
+	"fmt"
+	"time"
+)
+
+// reasonWatchdog is the reconnect reason when the server went silent
+const reasonWatchdog = "Watchdog: no messages from server"
+
+// watchdogProbeTimeout bounds the ping sent before declaring the connection dead
+const watchdogProbeTimeout = 5 * time.Second
+
+// SetWatchdogTimeout reconnects when nothing arrives from the server for timeoutMillis
+// After the silence the SDK sends a ping first, so an idle but healthy
+// connection isn't dropped; it only reconnects if the QUIC connection has
+// ended. 0 (default) disables.
+func (c *Client) SetWatchdogTimeout(timeoutMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.WatchdogTimeoutMs = timeoutMillis
+	})
+}
+
+// GetLastMessageAge returns milliseconds since the last message from the server
+// Counts from the connect if nothing arrived since; -1 if never connected.
+func (c *Client) GetLastMessageAge() int64 {
+	last := c.stats.lastMessageMs.Load()
+	if last == 0 {
+		return -1
+	}
+	return nowMillis() - last
+}
+
+// watchdogLoop tears down connections on which the server went silent
+func (c *Client) watchdogLoop() {
+	for c.shouldRun.Load() {
+		timeout := time.Duration(c.getConfig().WatchdogTimeoutMs) * time.Millisecond
+		interval := 5 * time.Second // re-check config periodically
+		if timeout > 0 {
+			interval = timeout / 4
+			if interval < time.Second {
+				interval = time.Second
+			}
+			if c.IsConnected() {
+				c.checkWatchdog(timeout, watchdogProbeTimeout)
+			}
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// checkWatchdog probes a silent connection and reconnects if it's dead
+// Only a QUIC connection that has ended (closed or idle timeout) counts as
+// dead. A ping is sent to refresh the silence, but a server that can't
+// answer it (protocol version 1, or not echoing the ref) is inconclusive.
+func (c *Client) checkWatchdog(timeout time.Duration, probeTimeout time.Duration) {
+	silence := time.Duration(c.GetLastMessageAge()) * time.Millisecond
+	if silence < timeout {
+		return
+	}
+
+	if c.checkMessageSupported("ping") == nil && c.PingSync(int(probeTimeout.Milliseconds())) >= 0 {
+		return
+	}
+
+	c.quicMutex.Lock()
+	conn := c.quicConn
+	c.quicMutex.Unlock()
+	if conn != nil && conn.Context().Err() == nil {
+		c.logSampled("watchdog-inconclusive", fmt.Sprintf("No messages from server for %v, but the connection is alive", silence.Round(time.Second)))
+		return
+	}
+
+	c.logError(fmt.Sprintf("No messages from server for %v and the connection is gone, reconnecting", silence.Round(time.Second)))
+	c.requestReconnect(reasonWatchdog)
+}
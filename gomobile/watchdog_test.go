@@ -0,0 +1,96 @@
+package vyxclient
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// silentServer is a QUIC server that reads the control stream and never answers
+func silentServer(t *testing.T) string {
+	t.Helper()
+	cert, _ := selfSignedCert(t)
+	ln, err := quic.ListenAddr("127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}, NextProtos: []string{"vyx-proxy"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept(context.Background())
+			if err != nil {
+				return
+			}
+			go func() {
+				stream, err := conn.AcceptStream(context.Background())
+				if err != nil {
+					return
+				}
+				io.Copy(io.Discard, stream)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// connectSilent attaches c to a silentServer as if it had authenticated
+func connectSilent(t *testing.T, c *Client, protoVersion int) *quic.Conn {
+	t.Helper()
+	conn, err := quic.DialAddr(context.Background(), silentServer(t), c.buildTLSConfig("localhost:443"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := conn.OpenStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.quicMutex.Lock()
+	c.quicConn = conn
+	c.quicMutex.Unlock()
+	c.setControlStream(stream)
+	c.protoVersion.Store(int32(protoVersion))
+	c.isConnected.Store(true)
+	// Last heard from the server a minute ago
+	c.stats.lastMessageMs.Store(nowMillis() - time.Minute.Milliseconds())
+	return conn
+}
+
+func TestWatchdogKeepsLiveConnection(t *testing.T) {
+	tests := []struct {
+		name         string
+		protoVersion int
+	}{
+		{"legacy server without ping", 1},
+		{"server not echoing the ref", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t)
+			defer c.Stop()
+			connectSilent(t, c, tt.protoVersion)
+
+			c.checkWatchdog(time.Second, 100*time.Millisecond)
+			if !c.IsConnected() {
+				t.Fatal("watchdog reconnected a live connection")
+			}
+		})
+	}
+}
+
+func TestWatchdogReconnectsDeadConnection(t *testing.T) {
+	c, _ := newTestClient(t)
+	defer c.Stop()
+	conn := connectSilent(t, c, 2)
+	conn.CloseWithError(0, "gone")
+
+	c.checkWatchdog(time.Second, 100*time.Millisecond)
+	if c.IsConnected() {
+		t.Fatal("watchdog kept a closed connection")
+	}
+}
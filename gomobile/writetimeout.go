@@ -0,0 +1,77 @@
+package vyxclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// reasonWriteTimeout is reported when the server stopped reading a stream
+const reasonWriteTimeout = "write_timeout"
+
+// errWriteTimeout means a stream write made no progress within the write timeout
+var errWriteTimeout = errors.New("stream write timed out")
+
+// SetWriteTimeout bounds how long a write to the server may block
+// When the server stops reading, flow control eventually blocks writes; after
+// timeoutMillis the send fails with SendErrTimeout and the client reconnects
+// (or, for a connection on its own stream, just that connection is closed)
+// instead of every sender hanging. Applies from the next connection. 0 disables.
+func (c *Client) SetWriteTimeout(timeoutMillis int) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.WriteTimeoutMs = timeoutMillis
+	})
+}
+
+// writeTimeout returns the configured write timeout, 0 if disabled
+func (c *Client) writeTimeout() time.Duration {
+	return time.Duration(c.getConfig().WriteTimeoutMs) * time.Millisecond
+}
+
+// deadlineWriter is a writer supporting write deadlines, like *quic.Stream
+type deadlineWriter interface {
+	io.Writer
+	SetWriteDeadline(t time.Time) error
+}
+
+// writeFullTimeout writes all of p, failing with errWriteTimeout if that
+// takes longer than timeout. Writers without deadlines and a timeout of 0
+// block as long as the write does.
+func writeFullTimeout(w io.Writer, p []byte, timeout time.Duration) error {
+	dw, ok := w.(deadlineWriter)
+	if !ok || timeout <= 0 {
+		return writeFull(w, p)
+	}
+
+	dw.SetWriteDeadline(time.Now().Add(timeout))
+	err := writeFull(w, p)
+	dw.SetWriteDeadline(time.Time{})
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return fmt.Errorf("%w after %v", errWriteTimeout, timeout)
+	}
+	return err
+}
+
+// connStreamWriteTimedOut drops a connection whose stream the server stopped reading
+// Its close goes on the control stream.
+func (c *Client) connStreamWriteTimedOut(id string) {
+	c.logError(fmt.Sprintf("Write to stream of %s timed out, closing connection", id))
+	c.dropConnStream(id)
+	go c.closeWithReason(id, reasonWriteTimeout)
+}
+
+// controlWriteTimedOut reconnects after a control stream write timed out
+// A partial write leaves the stream unusable, so the connection is replaced.
+// writer keeps failing with the same error, so only act while it's current.
+func (c *Client) controlWriteTimedOut(writer *coalescingWriter) {
+	c.quicMutex.Lock()
+	current := c.streamWriter == writer
+	c.quicMutex.Unlock()
+	if !current {
+		return
+	}
+	c.logError("Write to control stream timed out, reconnecting")
+	go c.requestReconnect("Control stream write timed out")
+}
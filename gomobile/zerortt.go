@@ -0,0 +1,64 @@
+package vyxclient
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/quic-go/quic-go"
+)
+
+// 0-RTT resumption
+//
+// With a session ticket from an earlier connection to the same server, the
+// client can send before the handshake finishes, saving a round trip on every
+// reconnect. The only thing sent that early is the auth message, and 0-RTT
+// data can be replayed by an attacker who captured it. The auth message
+// carries a random nonce, so servers accepting 0-RTT must reject reused
+// nonces; otherwise keep 0-RTT off. Everything after auth_success is sent
+// with full handshake keys.
+
+// SetZeroRTT sends the auth message as 0-RTT data when a session ticket is cached
+// Off by default; only enable it for servers rejecting replayed auth nonces.
+// If the server refuses 0-RTT, the client authenticates again once the
+// handshake completes. Takes effect on the next connect.
+func (c *Client) SetZeroRTT(enabled bool) {
+	c.updateConfig(func(cfg *clientConfig) {
+		cfg.ZeroRTT = enabled
+	})
+}
+
+// dialQUIC dials the server, returning early for 0-RTT if enabled
+// Without a usable ticket an early dial still completes the full handshake.
+func (c *Client) dialQUIC(ctx context.Context, transport *quic.Transport, addr net.Addr, tlsConf *tls.Config) (*quic.Conn, error) {
+	if c.getConfig().ZeroRTT {
+		return transport.DialEarly(ctx, addr, tlsConf, c.buildQUICConfig())
+	}
+	return transport.Dial(ctx, addr, tlsConf, c.buildQUICConfig())
+}
+
+// authenticateAfter0RTTRejected repeats authentication once the server refused 0-RTT
+// Everything sent early was discarded, so a new control stream is opened on
+// the completed connection.
+func (c *Client) authenticateAfter0RTTRejected(ctx context.Context, conn *quic.Conn) (*quic.Stream, *json.Decoder, error) {
+	c.log("Server rejected 0-RTT, authenticating after the handshake")
+	c.stats.zeroRTTRejected.Add(1)
+
+	conn, err := conn.NextConnection(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("handshake failed after 0-RTT rejection: %w", err)
+	}
+	stream, err := c.openStream(conn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open stream: %w", err)
+	}
+	c.setControlStream(stream)
+
+	decoder := c.newStreamDecoder(stream)
+	if err := c.authenticate(stream, decoder); err != nil {
+		return nil, nil, err
+	}
+	return stream, decoder, nil
+}